@@ -1,8 +1,14 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"bigfunbrewing.com/tictactoe"
 )
@@ -14,24 +20,210 @@ var splayer2 string
 var episodes int
 var gamma float64
 var epsilon float64
+var tiebreak string
+var playouts int
+var budget time.Duration
+var uct float64
+var rollout string
+var temperature float64
+var alpha float64
+var target string
+var lambda float64
+var replay int
+var replaybatch int
+var prioritybeta float64
+var syncevery int
+var prioritized bool
+var priority float64
+var baseline bool
+var rollouts int
+var depth int
+var n1 int
+var n2 int
+var opponent string
+var explore string
+var arch string
+var layers string
+var epsilon1 string
+var epsilon2 string
+var rate1 string
+var rate2 string
+var checkpoint string
+var every int
+var interval time.Duration
+var resume bool
+var seed int64
+var workers int
+var metrics string
+var evalevery int
 
 func init() {
 	flag.StringVar(&net1path, "net1", "", "path to the serialized player 1 NN. leave it blank to create a new one")
 	flag.StringVar(&net2path, "net2", "", "path to the serialized player 2 NN. leave it blank to create a new one")
-	flag.StringVar(&splayer1, "player1", "", "type of player to use for player 1. One of {randoplayer, mlannplayer}")
-	flag.StringVar(&splayer2, "player2", "", "type of player to use for player 2. One of {randoplayer, mlannplayer}")
+	flag.StringVar(&splayer1, "player1", "", "type of player to use for player 1. One of {randoplayer, mlannplayer, gruplayer, minimaxplayer, heuristicplayer, mctsplayer, alphazeroplayer, qtableplayer, policyplayer}")
+	flag.StringVar(&splayer2, "player2", "", "type of player to use for player 2. One of {randoplayer, mlannplayer, gruplayer, minimaxplayer, heuristicplayer, mctsplayer, alphazeroplayer, qtableplayer, policyplayer}")
 	flag.IntVar(&episodes, "episodes", 10000, "number of games to play with this pair of players")
 	flag.Float64Var(&gamma, "gamma", 0.5, "gamma is the discount rate on future rewards")
-	flag.Float64Var(&epsilon, "epsilon", 0.01, "epsilon is the exploration rate for NN players")
+	flag.Float64Var(&epsilon, "epsilon", 0.01, "epsilon is the exploration rate for NN players, the temperature for boltzmann and the exploration constant for ucb exploration")
+	flag.StringVar(&epsilon1, "epsilon1", "", "schedule for the exploration rate of player 1, overrides -epsilon. e.g. linear:0.1,0.01,10000, see README")
+	flag.StringVar(&epsilon2, "epsilon2", "", "schedule for the exploration rate of player 2, overrides -epsilon. e.g. linear:0.1,0.01,10000, see README")
+	flag.StringVar(&rate1, "rate1", "", "schedule for the network learning rate of an mlann player 1. e.g. exp:0.05,0.9999,0.001, see README")
+	flag.StringVar(&rate2, "rate2", "", "schedule for the network learning rate of an mlann player 2. e.g. exp:0.05,0.9999,0.001, see README")
+	flag.StringVar(&arch, "arch", "", "path to a JSON network config for new mlann players, see README. networks loaded from -net1 and -net2 keep the config they were saved with")
+	flag.StringVar(&layers, "layers", "", "comma separated hidden layer widths for new mlann players, e.g. 36,36,18. overrides the layers in -arch")
+	flag.StringVar(&checkpoint, "checkpoint", "checkpoint.json", "path of the trainer state saved with the players at every checkpoint and when interrupted")
+	flag.IntVar(&every, "every", 0, "save a checkpoint every this many episodes, 0 for never")
+	flag.DurationVar(&interval, "interval", 0, "save a checkpoint at least this often, e.g. 10m, 0 for never")
+	flag.BoolVar(&resume, "resume", false, "continue the run saved in -checkpoint with the flags it was started with, flags given now override them")
+	flag.Int64Var(&seed, "seed", 0, "seed for the random numbers of the run, 0 picks one from the clock")
+	flag.StringVar(&metrics, "metrics", "", "path of a file to record every batch to, CSV if it ends in .csv and JSON lines otherwise")
+	flag.IntVar(&evalevery, "evalevery", 0, "record the optimal move accuracy of learning players every this many episodes in -metrics, 0 for never")
+	flag.IntVar(&workers, "workers", 1, "number of goroutines playing games against snapshots of the players while they train")
+	flag.StringVar(&explore, "explore", "epsilon", "how mlann and gru players explore. One of {epsilon, boltzmann, ucb}")
+	flag.StringVar(&tiebreak, "tiebreak", "random", "how minimax players choose among equally good moves. One of {first, random, fastest}")
+	flag.IntVar(&playouts, "playouts", 1000, "number of playouts per move for MCTS players, 0 to only use -budget")
+	flag.DurationVar(&budget, "budget", 0, "time limit per move for MCTS players, 0 to only use -playouts")
+	flag.Float64Var(&uct, "uct", 1.4, "UCT exploration constant for MCTS players, PUCT constant for alphazero players")
+	flag.StringVar(&target, "target", "montecarlo", "how mlann players compute training targets. One of {montecarlo, td0, sarsa, qlearning, tdlambda}")
+	flag.Float64Var(&lambda, "lambda", 0.8, "lambda is the eligibility trace decay for the tdlambda target")
+	flag.IntVar(&replay, "replay", 0, "size of the experience replay buffer for mlann players, 0 trains on each batch of games once")
+	flag.IntVar(&replaybatch, "replaybatch", 256, "number of transitions replayed per training step")
+	flag.IntVar(&syncevery, "sync", 50, "number of training steps between syncs of the replay target network")
+	flag.BoolVar(&prioritized, "prioritized", false, "replay transitions in proportion to their TD error instead of uniformly")
+	flag.Float64Var(&priority, "priority", 0.6, "exponent applied to TD errors for prioritized replay")
+	flag.Float64Var(&prioritybeta, "prioritybeta", 0.4, "importance sampling exponent correcting the bias of prioritized replay, 1 for none left")
+	flag.IntVar(&rollouts, "rollouts", 0, "number of games gru players simulate from each candidate move, 0 scores each move once")
+	flag.IntVar(&depth, "depth", 0, "rounds of lookahead for mlann and gru players, 0 picks the best move greedily")
+	flag.IntVar(&n1, "n1", 3, "number of own moves expanded at each round of lookahead")
+	flag.IntVar(&n2, "n2", 3, "number of opponent replies expanded for each move during lookahead")
+	flag.StringVar(&opponent, "opponent", "min", "how lookahead expects the opponent to reply (min|self)")
+	flag.BoolVar(&baseline, "baseline", true, "train a critic as the baseline for policy players, turning REINFORCE into actor-critic")
+	flag.Float64Var(&alpha, "alpha", 0.5, "alpha is the learning rate for q-table players")
+	flag.Float64Var(&temperature, "temperature", 1.0, "move selection temperature for alphazero players, 0 always plays the most visited move")
+	flag.StringVar(&rollout, "rollout", "randoplayer", "player used to finish MCTS playouts. One of {randoplayer, minimaxplayer, heuristicplayer}")
 }
 
 func main() {
 	flag.Parse()
+
+	var state trainState
+	if resume {
+		var err error
+		if state, err = readState(checkpoint); err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		set := make(map[string]bool)
+		flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		for name, value := range state.Flags {
+			if !set[name] {
+				flag.Set(name, value)
+			}
+		}
+		if set["episodes"] {
+			state.Episodes = episodes
+		}
+		// the replay buffer, its target network and ucb counts are not
+		// saved, carrying on without them would not be the same run
+		if replay > 0 || explore == "ucb" {
+			fmt.Println("runs with -replay or -explore ucb cannot be resumed, their replay buffers and ucb counts are not saved")
+			os.Exit(1)
+		}
+		if state.Episode >= state.Episodes {
+			fmt.Println(checkpoint, "finished at episode", state.Episode, "raise -episodes to train further")
+			return
+		}
+		fmt.Println("resuming", checkpoint, "at episode", state.Episode, "of", state.Episodes)
+	} else {
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		state = trainState{Episodes: episodes, Seed: seed, Flags: make(map[string]string)}
+		flag.Visit(func(f *flag.Flag) { state.Flags[f.Name] = f.Value.String() })
+		delete(state.Flags, "resume")
+	}
+
 	if splayer1 == "" || splayer2 == "" {
 		flag.PrintDefaults()
 		return
 	}
 
+	tie, err := tictactoe.ParseTieBreak(tiebreak)
+	if err != nil {
+		fmt.Println(err.Error())
+		flag.PrintDefaults()
+		return
+	}
+
+	if _, err := tictactoe.ParseExplorer(explore, epsilon); err != nil {
+		fmt.Println(err.Error())
+		flag.PrintDefaults()
+		return
+	}
+	if explore == "ucb" && rollouts > 0 {
+		fmt.Println("-rollouts has no effect with -explore ucb, which picks every move itself")
+		flag.PrintDefaults()
+		return
+	}
+	if explore == "ucb" && depth > 0 {
+		fmt.Println("-depth has no effect with -explore ucb, which picks every move itself")
+		flag.PrintDefaults()
+		return
+	}
+	model, err := tictactoe.ParseOpponentModel(opponent)
+	if err != nil {
+		fmt.Println(err.Error())
+		flag.PrintDefaults()
+		return
+	}
+
+	cfg := tictactoe.DefaultNetConfig()
+	if arch != "" {
+		if cfg, err = tictactoe.LoadNetConfig(arch); err != nil {
+			fmt.Println(err.Error())
+			flag.PrintDefaults()
+			return
+		}
+	}
+	if layers != "" {
+		if cfg.Layers, err = tictactoe.ParseLayers(layers); err != nil {
+			fmt.Println(err.Error())
+			flag.PrintDefaults()
+			return
+		}
+	}
+
+	mode, err := tictactoe.ParseTargetMode(target)
+	if err != nil {
+		fmt.Println(err.Error())
+		flag.PrintDefaults()
+		return
+	}
+	if replay > 0 && target != "montecarlo" {
+		fmt.Println("-target has no effect with -replay, replay always trains towards the qlearning target")
+		flag.PrintDefaults()
+		return
+	}
+	if replay > 0 && replaybatch <= 0 {
+		fmt.Println("-replaybatch has to be positive")
+		flag.PrintDefaults()
+		return
+	}
+
+	var policy tictactoe.RolloutPolicy
+	switch rollout {
+	case "randoplayer":
+		policy = tictactoe.RandomRollout
+	case "minimaxplayer":
+		policy = func(pid int) tictactoe.Player { return tictactoe.NewMinimaxPlayer(pid, tie) }
+	case "heuristicplayer":
+		policy = func(pid int) tictactoe.Player { return tictactoe.NewHeuristicPlayer(pid) }
+	default:
+		fmt.Println("unknown rollout player", rollout)
+		flag.PrintDefaults()
+		return
+	}
+
 	// 1. Load two players
 	var player1 tictactoe.Player
 	var player2 tictactoe.Player
@@ -43,12 +235,33 @@ func main() {
 		if net1path == "" {
 			net1path = "player1.net"
 		}
-		player1 = tictactoe.NewMlannPlayer(1, net1path, epsilon, gamma)
+		player1 = tictactoe.NewMlannPlayerConfig(1, epsilon, gamma, cfg)
 	case "gruplayer":
 		if net1path == "" {
 			net1path = "gplayer1.net"
 		}
-		player1 = tictactoe.NewGruPlayer(1, net1path, epsilon)
+		player1 = tictactoe.NewGruPlayer(1, epsilon)
+	case "minimaxplayer":
+		player1 = tictactoe.NewMinimaxPlayer(1, tie)
+	case "heuristicplayer":
+		player1 = tictactoe.NewHeuristicPlayer(1)
+	case "mctsplayer":
+		player1 = tictactoe.NewMCTSPlayer(1, playouts, budget, uct, policy)
+	case "alphazeroplayer":
+		if net1path == "" {
+			net1path = "azplayer1.net"
+		}
+		player1 = tictactoe.NewAlphaZeroPlayer(1, playouts, uct, temperature)
+	case "qtableplayer":
+		if net1path == "" {
+			net1path = "qplayer1.json"
+		}
+		player1 = tictactoe.NewQTablePlayer(1, epsilon, alpha, gamma)
+	case "policyplayer":
+		if net1path == "" {
+			net1path = "pgplayer1.net"
+		}
+		player1 = tictactoe.NewPolicyPlayer(1, gamma, baseline)
 	}
 
 	switch splayer2 {
@@ -58,111 +271,206 @@ func main() {
 		if net2path == "" {
 			net2path = "player2.net"
 		}
-		player2 = tictactoe.NewMlannPlayer(2, net2path, epsilon, gamma)
+		player2 = tictactoe.NewMlannPlayerConfig(2, epsilon, gamma, cfg)
 	case "gruplayer":
 		if net2path == "" {
 			net2path = "gplayer2.net"
 		}
-		player2 = tictactoe.NewGruPlayer(2, net2path, epsilon)
+		player2 = tictactoe.NewGruPlayer(2, epsilon)
+	case "minimaxplayer":
+		player2 = tictactoe.NewMinimaxPlayer(2, tie)
+	case "heuristicplayer":
+		player2 = tictactoe.NewHeuristicPlayer(2)
+	case "mctsplayer":
+		player2 = tictactoe.NewMCTSPlayer(2, playouts, budget, uct, policy)
+	case "alphazeroplayer":
+		if net2path == "" {
+			net2path = "azplayer2.net"
+		}
+		player2 = tictactoe.NewAlphaZeroPlayer(2, playouts, uct, temperature)
+	case "qtableplayer":
+		if net2path == "" {
+			net2path = "qplayer2.json"
+		}
+		player2 = tictactoe.NewQTablePlayer(2, epsilon, alpha, gamma)
+	case "policyplayer":
+		if net2path == "" {
+			net2path = "pgplayer2.net"
+		}
+		player2 = tictactoe.NewPolicyPlayer(2, gamma, baseline)
 	}
 
-	// train the two players by having them play each other
-	fmt.Println(splayer1, "vs", splayer2)
-	trainplayers(player1, player2, episodes, gamma)
-
-	// Persist the results.
-	player1.Persist(net1path)
-	player2.Persist(net2path)
-}
+	// 2. Restore what they learned in earlier runs
+	if resume {
+		if err := state.check(net1path, net2path); err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+	}
+	if err := load(player1, net1path); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	if err := load(player2, net2path); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
 
-func trainplayers(player1, player2 tictactoe.Player, episodes int, gamma float64) {
-	cone := 0
-	ctwo := 0
-	cdraw := 0
-	games := make([]*tictactoe.GamePlayed, 0)
-	bsize := 20
-	var sone float64
-	var stwo float64
-	var sdraw float64
-	batches := 0
-	gamelen := 0
-	for i := 0; i < episodes; i++ {
-		//play a game and get the sequence of [board,mv] and who won
-		g, outcome := episode(player1, player2)
-
-		games = append(games, g)
-		gamelen += len(g.Positions())
-		switch outcome {
-		case 1:
-			cone++
-		case 2:
-			ctwo++
-		default:
-			cdraw++
-		}
-
-		if i > 0 && i%bsize == 0 {
-			player1.Train(games)
-			player2.Train(games)
-			pone := float64(cone) / float64(bsize)
-			ptwo := float64(ctwo) / float64(bsize)
-			pdraw := float64(cdraw) / float64(bsize)
-			sone += float64(cone)
-			stwo += float64(ctwo)
-			sdraw += float64(cdraw)
-			batches += 1
-
-			if i%1000 == 0 {
-				fmt.Printf("%d, %.2f, %.2f, %.2f   ,   %.2f, %.2f, %.2f, %.2f\n", i, sone/float64(i), stwo/float64(i), sdraw/float64(i), pone, ptwo, pdraw, float64(gamelen)/float64(i))
+	var la *tictactoe.Lookahead
+	if depth > 0 {
+		la = &tictactoe.Lookahead{Depth: depth, N1: n1, N2: n2, Opponent: model}
+	}
+	for _, p := range []tictactoe.Player{player1, player2} {
+		// each player gets its own explorer so ucb counts are not shared
+		e, _ := tictactoe.ParseExplorer(explore, epsilon)
+		if gp, ok := p.(*tictactoe.GruPlayer); ok {
+			gp.SetRollouts(rollouts)
+			gp.SetExplorer(e)
+			gp.SetLookahead(la)
+		}
+		if mp, ok := p.(*tictactoe.MlannPlayer); ok {
+			mp.SetExplorer(e)
+			mp.SetLookahead(la)
+			mp.SetTarget(mode, lambda)
+			if replay > 0 {
+				if err := mp.SetReplay(tictactoe.NewReplayBuffer(replay, prioritized, priority, prioritybeta), replaybatch, syncevery); err != nil {
+					fmt.Println(err.Error())
+					os.Exit(1)
+				}
 			}
-			cone = 0
-			ctwo = 0
-			cdraw = 0
-			games = make([]*tictactoe.GamePlayed, 0)
 		}
 	}
-	fmt.Printf("final: %d, one: %.2f, two: %.2f, draw: %.2f\n", batches, sone/float64(episodes), stwo/float64(episodes), sdraw/float64(episodes))
-}
 
-// episode plays a game of tic tac toe asking player1 and then player2 to move on a shared
-// board until the game has ended.
-func episode(player1, player2 tictactoe.Player) (g *tictactoe.GamePlayed, outcome int) {
-	b := tictactoe.NewBoard()
-	b.Reset()
-	w := 0
-	for w == 0 {
-		mv, err := player1.Move(b)
+	sched1, err := parseSchedules(epsilon1, rate1)
+	if err != nil {
+		fmt.Println(err.Error())
+		flag.PrintDefaults()
+		return
+	}
+	sched2, err := parseSchedules(epsilon2, rate2)
+	if err != nil {
+		fmt.Println(err.Error())
+		flag.PrintDefaults()
+		return
+	}
+
+	var sink *tictactoe.MetricsWriter
+	if metrics != "" {
+		// a resumed run carries on the file it started
+		f, err := openMetrics(metrics, resume)
 		if err != nil {
 			fmt.Println(err.Error())
-			break
+			os.Exit(1)
 		}
-
-		err = b.Move(mv)
+		defer f.Close()
+		info, err := f.Stat()
 		if err != nil {
 			fmt.Println(err.Error())
-			break
-		}
-		w = b.GameOver()
-		if w == -1 || w == 1 {
-			break
+			os.Exit(1)
 		}
-		mv, err = player2.Move(b)
-		if err != nil {
+		if sink, err = tictactoe.NewMetricsWriter(f, tictactoe.MetricsFormat(metrics), info.Size() == 0); err != nil {
 			fmt.Println(err.Error())
-			break
+			os.Exit(1)
 		}
+	}
 
-		err = b.Move(mv)
-		if err != nil {
-			fmt.Println(err.Error())
-			break
+	// train the two players by having them play each other, saving a
+	// checkpoint if the run is stopped
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	t := &trainer{
+		player1:   player1,
+		player2:   player2,
+		net1:      net1path,
+		net2:      net2path,
+		sched1:    sched1,
+		sched2:    sched2,
+		bsize:     20,
+		workers:   workers,
+		state:     state,
+		path:      checkpoint,
+		every:     every,
+		interval:  interval,
+		stop:      stop,
+		metrics:   sink,
+		evalEvery: evalevery,
+	}
+	fmt.Println(splayer1, "vs", splayer2)
+	if err := t.run(); err != nil {
+		fmt.Println(err.Error())
+		if errors.Is(err, errInterrupted) {
+			fmt.Println("continue with -resume")
 		}
-		w = b.GameOver()
-		if w == -1 || w == 2 {
-			break
+		os.Exit(1)
+	}
+
+	// Persist the results.
+	if err := t.checkpoint(); err != nil {
+		fmt.Println("error saving players,", err.Error())
+		os.Exit(1)
+	}
+}
+
+// openMetrics opens the metrics file at path, adding to it when resuming
+// and starting it again otherwise.
+func openMetrics(path string, resume bool) (*os.File, error) {
+	if resume {
+		return os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	}
+	return os.Create(path)
+}
+
+// load reads the saved state of p from path. A missing file is not an error,
+// the player starts from scratch and is saved there after training.
+func load(p tictactoe.Player, path string) error {
+	if path == "" {
+		return nil
+	}
+	err := p.Load(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		fmt.Println("no saved player at", path, "starting a new one")
+		return nil
+	}
+	return err
+}
+
+// schedules drives the exploration and learning rates of a player over a
+// training run. A nil schedule leaves the rate alone.
+type schedules struct {
+	epsilon tictactoe.Schedule
+	rate    tictactoe.Schedule
+}
+
+func parseSchedules(epsilon, rate string) (s schedules, err error) {
+	if epsilon != "" {
+		if s.epsilon, err = tictactoe.ParseSchedule(epsilon); err != nil {
+			return
 		}
 	}
-	outcome = w
-	g = b.GamePlayed()
+	if rate != "" {
+		s.rate, err = tictactoe.ParseSchedule(rate)
+	}
 	return
 }
+
+// apply sets the rates of p for the given episode. Changing the learning rate
+// rebuilds the network so it only happens every 1000 episodes.
+func (s schedules) apply(p tictactoe.Player, episode int) {
+	if e, ok := p.(interface{ SetEpsilon(float64) }); ok && s.epsilon != nil {
+		e.SetEpsilon(s.epsilon.At(episode))
+	}
+	if r, ok := p.(interface{ SetLearningRate(float64) }); ok && s.rate != nil && episode%1000 == 0 {
+		r.SetLearningRate(s.rate.At(episode))
+	}
+}
+
+// episode plays a game of tic tac toe between player1 and player2. A player
+// that fails to move ends the game early, the error is printed and the game
+// counts as a draw.
+func episode(player1, player2 tictactoe.Player) (*tictactoe.GamePlayed, int) {
+	g, outcome, err := tictactoe.PlayGame(player1, player2)
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+	return g, outcome
+}
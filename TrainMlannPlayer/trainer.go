@@ -0,0 +1,341 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"time"
+
+	"bigfunbrewing.com/tictactoe"
+)
+
+// errInterrupted is returned by run when training was stopped by a signal.
+var errInterrupted = errors.New("training interrupted")
+
+// trainState is everything besides the players needed to carry on a training
+// run where it stopped. It is saved as JSON at every checkpoint. The players
+// only save what Persist writes, runs whose players keep more than that, a
+// replay buffer or ucb counts, are refused by -resume.
+type trainState struct {
+	// Episode is the next episode to play and Episodes the number to play
+	// in total, they are equal once the run has finished.
+	Episode  int `json:"episode"`
+	Episodes int `json:"episodes"`
+	Batches  int `json:"batches"`
+	// One, Two and Draw tally the games won by each player and tied.
+	One     int `json:"one"`
+	Two     int `json:"two"`
+	Draw    int `json:"draw"`
+	GameLen int `json:"gamelen"`
+	// Seed is reset into math/rand as Seed+Episode after every batch so a
+	// resumed run draws the same numbers the uninterrupted run would have.
+	Seed int64 `json:"seed"`
+	// Flags are the command line flags the run was started with.
+	Flags map[string]string `json:"flags"`
+	// Models are the sha256 of the files player 1 and 2 were saved to with
+	// this state, empty for a player that is not saved. The players are
+	// saved before the state, so a checkpoint cut short in between leaves
+	// a model that does not match and check refuses to resume from it.
+	Models [2]string `json:"models"`
+	Saved  time.Time `json:"saved"`
+}
+
+func readState(path string) (s trainState, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s, err
+	}
+	if err = json.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("reading checkpoint %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// check returns an error if the models at net1 and net2 are not the ones
+// saved with the state.
+func (s trainState) check(net1, net2 string) error {
+	for i, path := range []string{net1, net2} {
+		if s.Models[i] == "" {
+			continue
+		}
+		sum, err := fileSum(path)
+		if err != nil {
+			return err
+		}
+		if sum != s.Models[i] {
+			return fmt.Errorf("%s does not match the checkpoint at episode %d, it was saved after the checkpoint or the checkpoint was cut short", path, s.Episode)
+		}
+	}
+	return nil
+}
+
+// fileSum returns the hex sha256 of the file at path, empty for no path.
+func fileSum(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// trainer trains two players against each other in batches of bsize games,
+// saving a checkpoint every few episodes or minutes and when stopped.
+type trainer struct {
+	player1, player2 tictactoe.Player
+	net1, net2       string
+	sched1, sched2   schedules
+	bsize            int
+	// workers is the number of goroutines playing games, at most 1 plays
+	// them on the learner
+	workers int
+	state   trainState
+	// path is where the state is saved, every and interval how often, 0
+	// for never. Checkpoints are only taken between batches.
+	path     string
+	every    int
+	interval time.Duration
+	// stop receives a signal when training should stop
+	stop <-chan os.Signal
+	// metrics, when set, records every batch, evalEvery is how many
+	// episodes apart the players are evaluated, 0 for never
+	metrics   *tictactoe.MetricsWriter
+	evalEvery int
+}
+
+// checkpoint saves both players and then the trainer state with the sums
+// of the files they were saved to.
+func (t *trainer) checkpoint() error {
+	if t.net1 != "" {
+		if err := t.player1.Persist(t.net1); err != nil {
+			return fmt.Errorf("saving player 1: %w", err)
+		}
+	}
+	if t.net2 != "" {
+		if err := t.player2.Persist(t.net2); err != nil {
+			return fmt.Errorf("saving player 2: %w", err)
+		}
+	}
+	if t.path == "" {
+		return nil
+	}
+	var err error
+	for i, path := range []string{t.net1, t.net2} {
+		if t.state.Models[i], err = fileSum(path); err != nil {
+			return err
+		}
+	}
+	t.state.Saved = time.Now().UTC()
+	data, err := json.MarshalIndent(t.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return tictactoe.WriteFileAtomic(t.path, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// interrupted saves a checkpoint after sig stopped training.
+func (t *trainer) interrupted(sig os.Signal) error {
+	fmt.Println("received", sig, "saving checkpoint at episode", t.state.Episode)
+	if err := t.checkpoint(); err != nil {
+		return err
+	}
+	return errInterrupted
+}
+
+// run plays the remaining episodes. Games played since the last batch was
+// trained are thrown away when a signal arrives or the run ends part way
+// through a batch, the state then holds the end of the last batch, which is
+// where a resumed run starts again.
+//
+// With more than one worker the games are played on snapshots of the players
+// and the schedules are only applied between batches. The games then depend
+// on how the goroutines are scheduled, so a resumed run no longer plays the
+// same games as an uninterrupted one.
+func (t *trainer) run() error {
+	s := &t.state
+	cone, ctwo, cdraw := 0, 0, 0
+	games := make([]*tictactoe.GamePlayed, 0)
+	last, lastTime, lastEval := s.Episode, time.Now(), s.Episode
+	rand.Seed(s.Seed + int64(s.Episode))
+	var workers *pool
+	if t.workers > 1 {
+		t.sched1.apply(t.player1, s.Episode)
+		t.sched2.apply(t.player2, s.Episode)
+		if workers = newPool(t.workers, t.player1, t.player2); workers == nil {
+			fmt.Println("the players cannot be copied, playing on one goroutine")
+		} else {
+			defer workers.stop()
+		}
+	}
+	for i := s.Episode; i < s.Episodes; i++ {
+		var g *tictactoe.GamePlayed
+		var outcome int
+		if workers == nil {
+			select {
+			case sig := <-t.stop:
+				return t.interrupted(sig)
+			default:
+			}
+
+			t.sched1.apply(t.player1, i)
+			t.sched2.apply(t.player2, i)
+
+			//play a game and get the sequence of [board,mv] and who won
+			g, outcome = episode(t.player1, t.player2)
+		} else {
+			select {
+			case sig := <-t.stop:
+				return t.interrupted(sig)
+			case r := <-workers.results:
+				g, outcome = r.game, r.outcome
+			}
+		}
+
+		games = append(games, g)
+		switch outcome {
+		case 1:
+			cone++
+		case 2:
+			ctwo++
+		default:
+			cdraw++
+		}
+
+		if i > 0 && i%t.bsize == 0 {
+			t.player1.Train(games)
+			t.player2.Train(games)
+			pone := float64(cone) / float64(t.bsize)
+			ptwo := float64(ctwo) / float64(t.bsize)
+			pdraw := float64(cdraw) / float64(t.bsize)
+			s.One += cone
+			s.Two += ctwo
+			s.Draw += cdraw
+			for _, g := range games {
+				s.GameLen += len(g.Positions())
+			}
+			s.Batches += 1
+			s.Episode = i + 1
+			rand.Seed(s.Seed + int64(s.Episode))
+			if workers != nil {
+				t.sched1.apply(t.player1, i)
+				t.sched2.apply(t.player2, i)
+				workers.refresh(t.player1, t.player2)
+			}
+
+			if t.metrics != nil {
+				eval := t.evalEvery > 0 && s.Episode-lastEval >= t.evalEvery
+				if eval {
+					lastEval = s.Episode
+				}
+				if err := t.record(games, cone, ctwo, cdraw, eval); err != nil {
+					return err
+				}
+			}
+
+			if i%1000 == 0 {
+				fmt.Printf("%d, %.2f, %.2f, %.2f   ,   %.2f, %.2f, %.2f, %.2f\n", i, float64(s.One)/float64(i), float64(s.Two)/float64(i), float64(s.Draw)/float64(i), pone, ptwo, pdraw, float64(s.GameLen)/float64(i))
+			}
+			cone = 0
+			ctwo = 0
+			cdraw = 0
+			games = make([]*tictactoe.GamePlayed, 0)
+
+			if (t.every > 0 && s.Episode-last >= t.every) || (t.interval > 0 && time.Since(lastTime) >= t.interval) {
+				fmt.Println("saving checkpoint at episode", s.Episode)
+				if err := t.checkpoint(); err != nil {
+					return err
+				}
+				last, lastTime = s.Episode, time.Now()
+			}
+		}
+	}
+	s.Episode = s.Episodes
+	fmt.Printf("final: %d, one: %.2f, two: %.2f, draw: %.2f\n", s.Batches, float64(s.One)/float64(s.Episodes), float64(s.Two)/float64(s.Episodes), float64(s.Draw)/float64(s.Episodes))
+	return nil
+}
+
+// record writes the metrics of the batch of games just trained on, with the
+// optimal move accuracy of the players when eval is set.
+func (t *trainer) record(games []*tictactoe.GamePlayed, one, two, draw int, eval bool) error {
+	n := float64(len(games))
+	moves := 0
+	for _, g := range games {
+		moves += len(g.Positions())
+	}
+	m := tictactoe.Metrics{
+		Episode:  t.state.Episode,
+		Batch:    t.state.Batches,
+		Time:     time.Now().UTC(),
+		One:      float64(one) / n,
+		Two:      float64(two) / n,
+		Draw:     float64(draw) / n,
+		GameLen:  float64(moves) / n,
+		Loss1:    lossOf(t.player1),
+		Loss2:    lossOf(t.player2),
+		Epsilon1: epsilonOf(t.player1),
+		Epsilon2: epsilonOf(t.player2),
+	}
+	if eval {
+		m.Eval1 = accuracyOf(t.player1, 1)
+		m.Eval2 = accuracyOf(t.player2, 2)
+	}
+	return t.metrics.Write(m)
+}
+
+// lossOf returns the training loss of p on the last batch, nil if it has none.
+func lossOf(p tictactoe.Player) *float64 {
+	if l, ok := p.(interface{ Loss() float64 }); ok {
+		if v := l.Loss(); !math.IsNaN(v) {
+			return &v
+		}
+	}
+	return nil
+}
+
+// epsilonOf returns the exploration rate of p, nil if it does not explore.
+func epsilonOf(p tictactoe.Player) *float64 {
+	if e, ok := p.(interface{ Epsilon() float64 }); ok {
+		v := e.Epsilon()
+		return &v
+	}
+	return nil
+}
+
+// accuracyOf returns the share of optimal moves a learning player makes when
+// it stops exploring, nil for players that do not learn. The player is
+// evaluated on a snapshot so it keeps exploring in training.
+func accuracyOf(p tictactoe.Player, pid int) *float64 {
+	if _, ok := p.(interface{ SetEpsilon(float64) }); !ok {
+		return nil
+	}
+	s, ok := tictactoe.Snapshot(p)
+	if !ok {
+		return nil
+	}
+	// a new explorer rather than a rate of 0, ucb explorers are shared
+	// with the snapshot
+	if e, ok := s.(interface{ SetExplorer(tictactoe.Explorer) }); ok {
+		e.SetExplorer(tictactoe.NewEpsilonGreedy(0))
+	} else {
+		s.(interface{ SetEpsilon(float64) }).SetEpsilon(0)
+	}
+	e, err := tictactoe.Evaluate(s, pid)
+	if err != nil {
+		fmt.Println("evaluating player", pid, err.Error())
+		return nil
+	}
+	v := e.Overall.Fraction()
+	return &v
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bigfunbrewing.com/tictactoe"
+)
+
+// interrupter signals stop once its player has trained on a number of
+// batches, so the trainer is interrupted at that batch boundary.
+type interrupter struct {
+	tictactoe.Player
+	batches int
+	stop    chan os.Signal
+}
+
+func (ip *interrupter) Train(games []*tictactoe.GamePlayed) {
+	ip.Player.Train(games)
+	ip.batches--
+	if ip.batches == 0 {
+		ip.stop <- os.Interrupt
+	}
+}
+
+// newTestTrainer returns a trainer of two q-table players saved in dir,
+// loading them and the state from there when resuming.
+func newTestTrainer(t *testing.T, dir string, episodes int, resume bool) *trainer {
+	tr := &trainer{
+		player1: tictactoe.NewQTablePlayer(1, 0.2, 0.5, 0.9),
+		player2: tictactoe.NewQTablePlayer(2, 0.2, 0.5, 0.9),
+		net1:    filepath.Join(dir, "player1.json"),
+		net2:    filepath.Join(dir, "player2.json"),
+		bsize:   20,
+		workers: 1,
+		state:   trainState{Episodes: episodes, Seed: 7},
+		path:    filepath.Join(dir, "checkpoint.json"),
+		stop:    make(chan os.Signal, 1),
+	}
+	if resume {
+		var err error
+		if tr.state, err = readState(tr.path); err != nil {
+			t.Fatal(err.Error())
+		}
+		if err := tr.state.check(tr.net1, tr.net2); err != nil {
+			t.Fatal(err.Error())
+		}
+		if err := tr.player1.Load(tr.net1); err != nil {
+			t.Fatal(err.Error())
+		}
+		if err := tr.player2.Load(tr.net2); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	return tr
+}
+
+func TestTrainerResume(t *testing.T) {
+	whole := newTestTrainer(t, t.TempDir(), 200, false)
+	if err := whole.run(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := whole.checkpoint(); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// stop after the third batch and carry on from the checkpoint
+	dir := t.TempDir()
+	first := newTestTrainer(t, dir, 200, false)
+	stop := make(chan os.Signal, 1)
+	first.stop = stop
+	first.player1 = &interrupter{Player: first.player1, batches: 3, stop: stop}
+	if err := first.run(); !errors.Is(err, errInterrupted) {
+		t.Fatalf("expected the run to be interrupted, got %v", err)
+	}
+	if first.state.Episode != 61 {
+		t.Errorf("expected the checkpoint at the end of the third batch, episode 61, got %d", first.state.Episode)
+	}
+	// a player saved after the state is caught on resume
+	state, err := readState(first.path)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	saved, _ := os.ReadFile(first.net2)
+	if err := first.player2.Persist(first.net2); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := state.check(first.net1, first.net2); err != nil {
+		t.Errorf("expected a model saved again unchanged to match, got %v", err)
+	}
+	g, _, err := tictactoe.PlayGame(tictactoe.NewRandomPlayer(1), first.player2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	first.player2.Train([]*tictactoe.GamePlayed{g})
+	if err := first.player2.Persist(first.net2); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := state.check(first.net1, first.net2); err == nil {
+		t.Errorf("expected a model saved after the checkpoint to be refused")
+	}
+	if err := os.WriteFile(first.net2, saved, 0644); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	resumed := newTestTrainer(t, dir, 200, true)
+	if err := resumed.run(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := resumed.checkpoint(); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	w, r := whole.state, resumed.state
+	if w.Episode != w.Episodes || r.Episode != r.Episodes {
+		t.Errorf("expected finished runs to be at their last episode, got %d and %d of %d", w.Episode, r.Episode, w.Episodes)
+	}
+	if w.Batches != r.Batches || w.One != r.One || w.Two != r.Two || w.Draw != r.Draw || w.GameLen != r.GameLen {
+		t.Errorf("resumed run differs from the uninterrupted one, %+v and %+v", w, r)
+	}
+	for _, name := range []string{"player1.json", "player2.json"} {
+		a, _ := os.ReadFile(filepath.Join(filepath.Dir(whole.net1), name))
+		b, _ := os.ReadFile(filepath.Join(dir, name))
+		if len(a) == 0 || !bytes.Equal(a, b) {
+			t.Errorf("resumed run saved a different %s", name)
+		}
+	}
+}
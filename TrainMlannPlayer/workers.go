@@ -0,0 +1,82 @@
+package main
+
+import (
+	"sync"
+
+	"bigfunbrewing.com/tictactoe"
+)
+
+// result is a game played by a worker and who won it.
+type result struct {
+	game    *tictactoe.GamePlayed
+	outcome int
+}
+
+// pool plays games on several goroutines. Every worker plays its own
+// snapshots of the two players, which the learner replaces after each
+// training batch, so the players themselves are only touched by the learner.
+type pool struct {
+	mu        sync.Mutex
+	snapshots [][2]tictactoe.Player
+	results   chan result
+	done      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// newPool starts workers playing player1 against player2. It returns nil if
+// either player cannot be snapshot.
+func newPool(workers int, player1, player2 tictactoe.Player) *pool {
+	p := &pool{
+		snapshots: make([][2]tictactoe.Player, workers),
+		results:   make(chan result, workers),
+		done:      make(chan struct{}),
+	}
+	if !p.refresh(player1, player2) {
+		return nil
+	}
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go p.work(i)
+	}
+	return p
+}
+
+// refresh gives every worker new snapshots of the players, they are used
+// from the next game the worker starts.
+func (p *pool) refresh(player1, player2 tictactoe.Player) bool {
+	snapshots := make([][2]tictactoe.Player, len(p.snapshots))
+	for i := range snapshots {
+		s1, ok1 := tictactoe.Snapshot(player1)
+		s2, ok2 := tictactoe.Snapshot(player2)
+		if !ok1 || !ok2 {
+			return false
+		}
+		snapshots[i] = [2]tictactoe.Player{s1, s2}
+	}
+	p.mu.Lock()
+	p.snapshots = snapshots
+	p.mu.Unlock()
+	return true
+}
+
+func (p *pool) work(i int) {
+	defer p.wg.Done()
+	for {
+		p.mu.Lock()
+		players := p.snapshots[i]
+		p.mu.Unlock()
+		g, outcome := episode(players[0], players[1])
+		select {
+		case p.results <- result{game: g, outcome: outcome}:
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// stop waits for the workers to finish the games they are playing and
+// throws those games away.
+func (p *pool) stop() {
+	close(p.done)
+	p.wg.Wait()
+}
@@ -0,0 +1,271 @@
+package tictactoe
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/rand"
+
+	"bigfunbrewing.com/tensor"
+)
+
+// AlphaZeroPlayer pairs a policy/value network with a Monte Carlo Tree Search
+// in the style of AlphaZero. The network reads the board from the point of
+// view of the player to move and produces ten outputs, the first nine are the
+// policy head, a prior for each cell, and the last is the value head, the
+// expected outcome in [-1,1] for the player to move. The search uses the
+// priors to steer the PUCT selection rule and the value in place of random
+// playouts.
+//
+// It learns only from self-play. Every search leaves behind the distribution
+// of visits at the root, and Train fits the policy head to those
+// distributions and the value head to the final outcome of each game.
+type AlphaZeroPlayer struct {
+	pid      int
+	net      *tensor.Network[float64]
+	playouts int
+	// cpuct scales how much the priors drive exploration
+	cpuct float64
+	// temperature above 0 samples moves in proportion to visits^(1/T) which
+	// keeps self-play games varied, 0 always plays the most visited move.
+	temperature float64
+	// policies holds the search distribution for each position this player
+	// has moved from since it was last trained.
+	policies map[grid][9]float64
+	// episodes is the number of games the network has trained on
+	episodes int
+}
+
+// NewAlphaZeroPlayer returns a player with a new network, call Load to play a
+// saved one.
+func NewAlphaZeroPlayer(pid int, playouts int, cpuct, temperature float64) *AlphaZeroPlayer {
+	alpha := 0.01
+	lambda := 0.3
+	net := tensor.NewNetwork(
+		tensor.SquaredError[float64],
+		tensor.SquaredErrorPrime[float64],
+		50,
+		tensor.NewDense[float64](9, 36, 1.0, 0.1, tensor.LeakyRelu[float64]{Leak: 0.1}, "adam", alpha, lambda),
+		tensor.NewDense[float64](36, 36, 1.0, 0.1, tensor.LeakyRelu[float64]{Leak: 0.1}, "adam", alpha, lambda),
+		tensor.NewDense[float64](36, 10, 1.0, 0.1, tensor.Linear[float64]{}, "adam", alpha, lambda),
+	)
+
+	if playouts <= 0 {
+		playouts = 100
+	}
+	if playouts < 2 {
+		// the first playout only expands the root
+		playouts = 2
+	}
+	ap := &AlphaZeroPlayer{
+		pid:         pid,
+		net:         net,
+		playouts:    playouts,
+		cpuct:       cpuct,
+		temperature: temperature,
+		policies:    make(map[grid][9]float64),
+	}
+	return ap
+}
+
+// Load reads the player's network from the model file at path. Files
+// without a model header are refused.
+func (ap *AlphaZeroPlayer) Load(path string) error {
+	h, payload, legacy, err := openModel(path, "alphazeroplayer")
+	if err != nil {
+		return err
+	}
+	if legacy {
+		// alphazero players have always saved a header
+		return &ModelError{Path: path, Kind: ModelLegacy, Detail: "no model header, not an alphazeroplayer file"}
+	}
+	ap.net.Read(bytes.NewReader(payload))
+	ap.episodes = h.Episodes
+	return nil
+}
+
+// SetTemperature changes how greedily moves are picked from the search.
+func (ap *AlphaZeroPlayer) SetTemperature(temperature float64) {
+	ap.temperature = temperature
+}
+
+// evaluate runs the network on g and returns the priors over the empty cells
+// and the value of g for the player to move.
+func (ap *AlphaZeroPlayer) evaluate(g grid) (priors [9]float64, value float64) {
+	out := ap.net.Forward(g.perspective(g.toMove()))
+	empty := g.empty()
+	sum := 0.0
+	for _, idx := range empty {
+		priors[idx] = math.Max(out.Get(idx, 0), 1e-3)
+		sum += priors[idx]
+	}
+	for _, idx := range empty {
+		priors[idx] /= sum
+	}
+	value = math.Max(-1, math.Min(1, out.Get(9, 0)))
+	return
+}
+
+// search runs the configured number of PUCT simulations from g.
+func (ap *AlphaZeroPlayer) search(g grid) *node {
+	root := newNode(g, -1, nil)
+	for i := 0; i < ap.playouts; i++ {
+		n := root
+		for len(n.children) > 0 {
+			n = ap.selectChild(n)
+		}
+		if w := n.g.winner(); w != 0 {
+			backup(n, reward(n, w))
+			continue
+		}
+		priors, value := ap.evaluate(n.g)
+		for _, idx := range n.untried {
+			c := n.g
+			c[idx] = n.toMove
+			child := newNode(c, idx, n)
+			child.prior = priors[idx]
+			n.children = append(n.children, child)
+		}
+		n.untried = nil
+		// value is for the player to move at n, the reward belongs to the
+		// player who moved into n
+		backup(n, (1-value)/2)
+	}
+	return root
+}
+
+// selectChild applies the PUCT rule, Q(s,a) + cpuct*P(s,a)*sqrt(N(s))/(1+N(s,a)).
+// Unvisited children count as a tie.
+func (ap *AlphaZeroPlayer) selectChild(n *node) *node {
+	var best *node
+	bv := math.Inf(-1)
+	for _, child := range n.children {
+		q := 0.5
+		if child.visits > 0 {
+			q = child.value / child.visits
+		}
+		v := q + ap.cpuct*child.prior*math.Sqrt(n.visits)/(1+child.visits)
+		if v > bv {
+			best, bv = child, v
+		}
+	}
+	return best
+}
+
+// Move searches from the current board, remembers the visit distribution for
+// training and plays a move according to the temperature.
+func (ap *AlphaZeroPlayer) Move(b Board) (mv *Move, err error) {
+	if _, err = ValidMoves(b, ap.pid); err != nil {
+		return nil, err
+	}
+	g := gridOf(b)
+	root := ap.search(g)
+	var pi [9]float64
+	for _, child := range root.children {
+		pi[child.idx] = child.visits / (root.visits - 1)
+	}
+	ap.policies[g] = pi
+
+	best := root.children[0]
+	for _, child := range root.children[1:] {
+		if child.visits > best.visits {
+			best = child
+		}
+	}
+	if ap.temperature > 0 {
+		weights := make([]float64, len(root.children))
+		sum := 0.0
+		for i, child := range root.children {
+			weights[i] = math.Pow(child.visits, 1/ap.temperature)
+			sum += weights[i]
+		}
+		r := rand.Float64() * sum
+		for i, child := range root.children {
+			r -= weights[i]
+			if r <= 0 {
+				best = child
+				break
+			}
+		}
+	}
+	return g.move(ap.pid, best.idx), nil
+}
+
+// Train fits the network to the positions of both players in games. The
+// policy target is the search distribution recorded when the position was
+// played, or the move actually played when this player did not search it.
+// The value target is the outcome of the game for the player to move. Each
+// position is added under all eight symmetries of the board.
+func (ap *AlphaZeroPlayer) Train(games []*GamePlayed) {
+	ap.episodes += len(games)
+	var sample *tensor.Sample[float64]
+	for i := range games {
+		for _, p := range games[i].Positions() {
+			g, idx, pid := decode(p)
+			pi, ok := ap.policies[g]
+			if !ok {
+				pi = [9]float64{}
+				pi[idx] = 1
+			}
+			z := 0.0
+			switch games[i].Outcome() {
+			case float64(pid):
+				z = 1
+			case float64(other(pid)):
+				z = -1
+			}
+			for k := range symmetries {
+				y := make([]float64, 10)
+				for j := range pi {
+					y[symmetries[k][j]] = pi[j]
+				}
+				y[9] = z
+				s := g.transform(k)
+				sample = appendSample(sample, s.perspective(pid), y)
+			}
+		}
+	}
+	if sample != nil {
+		ap.net.Iterate(sample)
+	}
+	ap.policies = make(map[grid][9]float64)
+}
+
+func (ap *AlphaZeroPlayer) Persist(path string) error {
+	fmt.Println("saving network to file", path)
+	var payload bytes.Buffer
+	ap.net.Write(&payload)
+	h := ModelHeader{
+		Type:     "alphazeroplayer",
+		Pid:      ap.pid,
+		Hyper:    map[string]float64{"playouts": float64(ap.playouts), "cpuct": ap.cpuct, "temperature": ap.temperature},
+		Episodes: ap.episodes,
+	}
+	return saveModel(path, h, payload.Bytes())
+}
+
+// Display shows the network prior for each empty cell followed by the value
+// the network gives the board for the player to move.
+func (ap *AlphaZeroPlayer) Display(b Board) {
+	g := gridOf(b)
+	priors, value := ap.evaluate(g)
+	fmt.Println("         |     0     |     1     |     2     ")
+	fmt.Println("---------+-----------+-----------+-----------")
+	for i := 0; i < 3; i++ {
+		cells := make([]string, 3)
+		for j := 0; j < 3; j++ {
+			p, _ := b.Get(i, j)
+			cells[j] = convert(p)
+			if cells[j] == "" {
+				cells[j] = fmt.Sprintf("%9.5f", priors[loc(i, j)])
+			}
+		}
+		fmt.Printf("    %d    | %s | %s | %s\n", i, cells[0], cells[1], cells[2])
+		if i < 2 {
+			fmt.Println("---------+---------+---------+---------")
+		} else {
+			fmt.Println()
+		}
+	}
+	fmt.Printf("value: %.5f\n\n", value)
+}
@@ -0,0 +1,51 @@
+package tictactoe
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAlphaZeroEvaluate(t *testing.T) {
+	ap := NewAlphaZeroPlayer(1, 20, 1.5, 0)
+	g := gridOf(&BoardImp{data: [][]int{
+		{1, 2, 0},
+		{0, 1, 0},
+		{2, 0, 0},
+	}})
+	priors, value := ap.evaluate(g)
+	sum := 0.0
+	for i := range priors {
+		if g[i] != 0 && priors[i] != 0 {
+			t.Errorf("occupied cell %d has prior %.5f", i, priors[i])
+		}
+		sum += priors[i]
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Errorf("expected priors to sum to 1, got %.5f", sum)
+	}
+	if value < -1 || value > 1 {
+		t.Errorf("value %.5f outside [-1,1]", value)
+	}
+}
+
+func TestAlphaZeroSelfPlay(t *testing.T) {
+	player1 := NewAlphaZeroPlayer(1, 20, 1.5, 1.0)
+	player2 := NewAlphaZeroPlayer(2, 20, 1.5, 1.0)
+	g := recordGame(t, player1, player2)
+	if len(player1.policies) == 0 {
+		t.Fatalf("no search policies were recorded")
+	}
+	for g, pi := range player1.policies {
+		sum := 0.0
+		for i := range pi {
+			sum += pi[i]
+		}
+		if math.Abs(sum-1) > 1e-9 {
+			t.Errorf("%v: search policy sums to %.5f", g, sum)
+		}
+	}
+	player1.Train([]*GamePlayed{g})
+	if len(player1.policies) != 0 {
+		t.Errorf("expected Train to consume the recorded policies")
+	}
+}
@@ -229,3 +229,14 @@ func (b *BoardImp) GameOver() int {
 	}
 	return 0
 }
+
+// cloneBoard returns a copy of b, including the positions played so far, that
+// can be moved on without changing b.
+func cloneBoard(b Board) *BoardImp {
+	g := gridOf(b)
+	out := g.board()
+	if gp := b.GamePlayed(); gp != nil {
+		out.g.positions = append(out.g.positions, gp.Positions()...)
+	}
+	return out
+}
@@ -0,0 +1,224 @@
+package tictactoe
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// CalibrationPoint is the score a player gave a move next to the exact value
+// of the move.
+type CalibrationPoint struct {
+	// Key is the board before the move as from stateKey, Depth the number
+	// of moves made before it.
+	Key   string
+	Depth int
+	Move  *Move
+	Score float64
+	// Value is the exact value of the move from Searcher, positive for a
+	// win and larger the sooner it comes, 0 for a tie, negative for a loss.
+	Value int
+}
+
+// Calibration sums up how well a player's scores track the exact values.
+//
+// Spearman is the rank correlation between scores and values, 1 when the
+// player orders moves exactly like perfect play. Agreement is the share of
+// winning and losing moves whose score has the right sign, ties are left
+// out since the players score a tie slightly above 0.
+//
+// The players do not learn one score per outcome, an mlann player's target
+// for a win shrinks with the length of the game while a loss is always the
+// full penalty, so the scores are not compared with a fixed scale. Win, Draw
+// and Loss are instead the mean score of the moves with that outcome, the
+// least squares fit of one score per outcome, and 0 when there are no such
+// moves. MAE and RMSE are the mean absolute and root mean squared errors of
+// the scores around that fit, small when the player scores moves with the
+// same outcome alike.
+type Calibration struct {
+	Moves     int
+	Spearman  float64
+	Agreement float64
+	Win       float64
+	Draw      float64
+	Loss      float64
+	MAE       float64
+	RMSE      float64
+}
+
+// CalibrationReport holds every point and the calibration over all of them
+// and for each depth.
+type CalibrationReport struct {
+	Pid     int
+	Points  []CalibrationPoint
+	Overall Calibration
+	// ByDepth is indexed by the number of moves made before the position.
+	ByDepth [9]Calibration
+}
+
+// Calibrate scores every valid move in every reachable position where pid
+// is to move with e and compares the scores to the exact values.
+func Calibrate(e MoveEvaluator, pid int) *CalibrationReport {
+	s := NewSearcher()
+	out := &CalibrationReport{Pid: pid, Points: make([]CalibrationPoint, 0)}
+	reachable(grid{}, make(map[grid]bool), func(g grid) {
+		if g.toMove() != pid {
+			return
+		}
+		b := g.board()
+		moves, values, err := s.MoveValues(b, pid)
+		if err != nil {
+			return
+		}
+		for i := range moves {
+			out.Points = append(out.Points, CalibrationPoint{
+				Key:   stateKey(g),
+				Depth: 9 - len(g.empty()),
+				Move:  moves[i],
+				Score: e.EvalMove(b, moves[i]),
+				Value: values[i],
+			})
+		}
+	})
+	out.Overall = calibration(out.Points)
+	for d := range out.ByDepth {
+		points := make([]CalibrationPoint, 0)
+		for _, p := range out.Points {
+			if p.Depth == d {
+				points = append(points, p)
+			}
+		}
+		out.ByDepth[d] = calibration(points)
+	}
+	return out
+}
+
+func calibration(points []CalibrationPoint) (c Calibration) {
+	c.Moves = len(points)
+	if c.Moves == 0 {
+		return
+	}
+	scores := make([]float64, len(points))
+	values := make([]float64, len(points))
+	// sum and count of the scores by outcome, 0: loss, 1: draw, 2: win
+	var sum [3]float64
+	var count [3]int
+	decided, agree := 0, 0
+	for i, p := range points {
+		scores[i] = p.Score
+		values[i] = float64(p.Value)
+		outcome := sign(p.Value)
+		if outcome != 0 {
+			decided++
+			if (p.Score > 0) == (outcome > 0) {
+				agree++
+			}
+		}
+		sum[outcome+1] += p.Score
+		count[outcome+1]++
+	}
+	var fit [3]float64
+	for k := range fit {
+		if count[k] > 0 {
+			fit[k] = sum[k] / float64(count[k])
+		}
+	}
+	c.Loss, c.Draw, c.Win = fit[0], fit[1], fit[2]
+	for _, p := range points {
+		diff := p.Score - fit[sign(p.Value)+1]
+		c.MAE += math.Abs(diff)
+		c.RMSE += diff * diff
+	}
+	n := float64(c.Moves)
+	c.MAE /= n
+	c.RMSE = math.Sqrt(c.RMSE / n)
+	if decided > 0 {
+		c.Agreement = float64(agree) / float64(decided)
+	}
+	c.Spearman = pearson(ranks(scores), ranks(values))
+	return
+}
+
+// ranks returns the rank of each of xs counting from 1, tied values share
+// the mean of their ranks.
+func ranks(xs []float64) []float64 {
+	order := make([]int, len(xs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return xs[order[a]] < xs[order[b]] })
+	out := make([]float64, len(xs))
+	for i := 0; i < len(order); {
+		j := i
+		for j < len(order) && xs[order[j]] == xs[order[i]] {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			out[order[k]] = rank
+		}
+		i = j
+	}
+	return out
+}
+
+// pearson returns the correlation of xs and ys, 0 if either is constant.
+func pearson(xs, ys []float64) float64 {
+	n := float64(len(xs))
+	mx, my := 0.0, 0.0
+	for i := range xs {
+		mx += xs[i]
+		my += ys[i]
+	}
+	mx /= n
+	my /= n
+	sxy, sxx, syy := 0.0, 0.0, 0.0
+	for i := range xs {
+		sxy += (xs[i] - mx) * (ys[i] - my)
+		sxx += (xs[i] - mx) * (xs[i] - mx)
+		syy += (ys[i] - my) * (ys[i] - my)
+	}
+	if sxx == 0 || syy == 0 {
+		return 0
+	}
+	return sxy / math.Sqrt(sxx*syy)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', 6, 64)
+}
+
+// WriteCalibrationCSV writes the calibration of each report for each depth
+// followed by the overall calibration, with depth "all".
+func WriteCalibrationCSV(w io.Writer, reports ...*CalibrationReport) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"pid", "depth", "moves", "spearman", "agreement", "win", "draw", "loss", "mae", "rmse"})
+	for _, cr := range reports {
+		row := func(depth string, c Calibration) {
+			cw.Write([]string{strconv.Itoa(cr.Pid), depth, strconv.Itoa(c.Moves), formatFloat(c.Spearman), formatFloat(c.Agreement), formatFloat(c.Win), formatFloat(c.Draw), formatFloat(c.Loss), formatFloat(c.MAE), formatFloat(c.RMSE)})
+		}
+		for d, c := range cr.ByDepth {
+			if c.Moves > 0 {
+				row(strconv.Itoa(d), c)
+			}
+		}
+		row("all", cr.Overall)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteCalibrationPointsCSV writes every move scored in the reports.
+func WriteCalibrationPointsCSV(w io.Writer, reports ...*CalibrationReport) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"pid", "board", "depth", "row", "col", "score", "value", "outcome"})
+	for _, cr := range reports {
+		for _, p := range cr.Points {
+			cw.Write([]string{strconv.Itoa(cr.Pid), p.Key, strconv.Itoa(p.Depth), strconv.Itoa(p.Move.Row), strconv.Itoa(p.Move.Col), formatFloat(p.Score), strconv.Itoa(p.Value), strconv.Itoa(sign(p.Value))})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"bigfunbrewing.com/tictactoe"
+)
+
+func main() {
+	pid := flag.Int("pid", 0, "calibrate the player as player 1 or 2, 0 for every side it can play")
+	csvpath := flag.String("csv", "", "path to write the calibration by depth to as CSV")
+	points := flag.String("points", "", "path to write the score and exact value of every move to as CSV")
+	flag.Usage = func() {
+		fmt.Println("usage: calibrate [flags] spec")
+		fmt.Println()
+		fmt.Println("spec is a player that scores moves and the path of its model, e.g. mlannplayer:player1.net, mlannplayer:player{pid}.net or gruplayer:gplayer2.net")
+		fmt.Println()
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 || *pid < 0 || *pid > 2 {
+		flag.Usage()
+		return
+	}
+	spec := flag.Arg(0)
+	pids := []int{1, 2}
+	if *pid != 0 {
+		pids = []int{*pid}
+	}
+
+	reports := make([]*tictactoe.CalibrationReport, 0)
+	for _, id := range pids {
+		p, err := tictactoe.NewPlayerFromSpec(spec, id)
+		if *pid == 0 && tictactoe.WrongSide(err) {
+			// a model trained for the other side only plays that side
+			continue
+		}
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		e, ok := p.(tictactoe.MoveEvaluator)
+		if !ok {
+			fmt.Println(spec, "does not score moves")
+			os.Exit(1)
+		}
+		cr := tictactoe.Calibrate(e, id)
+		report(spec, cr)
+		reports = append(reports, cr)
+	}
+
+	if *csvpath != "" {
+		if err := writeCSV(*csvpath, tictactoe.WriteCalibrationCSV, reports); err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+	}
+	if *points != "" {
+		if err := writeCSV(*points, tictactoe.WriteCalibrationPointsCSV, reports); err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+	}
+}
+
+// report prints the calibration of the player by depth and overall.
+func report(spec string, cr *tictactoe.CalibrationReport) {
+	fmt.Printf("%s as player %d, %d moves scored\n", spec, cr.Pid, cr.Overall.Moves)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "depth\tmoves\tspearman\tsign agreement\twin\tdraw\tloss\tmae\trmse\t")
+	row := func(depth string, c tictactoe.Calibration) {
+		fmt.Fprintf(w, "%s\t%d\t%.3f\t%.3f\t%.3f\t%.3f\t%.3f\t%.3f\t%.3f\t\n", depth, c.Moves, c.Spearman, c.Agreement, c.Win, c.Draw, c.Loss, c.MAE, c.RMSE)
+	}
+	for d, c := range cr.ByDepth {
+		if c.Moves > 0 {
+			row(fmt.Sprint(d), c)
+		}
+	}
+	row("all", cr.Overall)
+	w.Flush()
+	fmt.Println()
+}
+
+func writeCSV(path string, write func(w io.Writer, reports ...*tictactoe.CalibrationReport) error, reports []*tictactoe.CalibrationReport) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f, reports...); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
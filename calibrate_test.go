@@ -0,0 +1,99 @@
+package tictactoe
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+// exactEvaluator scores moves with their exact value times factor.
+type exactEvaluator struct {
+	search *Searcher
+	factor float64
+}
+
+func (ee *exactEvaluator) EvalMove(b Board, mv *Move) float64 {
+	moves, values, _ := ee.search.MoveValues(b, mv.Pid)
+	for i := range moves {
+		if *moves[i] == *mv {
+			return ee.factor * float64(values[i])
+		}
+	}
+	return 0
+}
+
+// outcomeEvaluator scores winning and losing moves with fixed but different
+// scores, as a player does whose win and loss targets are not symmetric.
+type outcomeEvaluator struct {
+	exactEvaluator
+	win, loss float64
+}
+
+func (oe *outcomeEvaluator) EvalMove(b Board, mv *Move) float64 {
+	switch v := oe.exactEvaluator.EvalMove(b, mv); {
+	case v > 0:
+		return oe.win
+	case v < 0:
+		return oe.loss
+	}
+	return 0
+}
+
+func TestRanks(t *testing.T) {
+	out := ranks([]float64{3, 1, 2, 1})
+	want := []float64{4, 1.5, 3, 1.5}
+	for i := range want {
+		if out[i] != want[i] {
+			t.Errorf("expected ranks %v, got %v", want, out)
+			break
+		}
+	}
+	if r := pearson([]float64{1, 2, 3}, []float64{2, 4, 6}); math.Abs(r-1) > 1e-9 {
+		t.Errorf("expected a correlation of 1, got %f", r)
+	}
+	if r := pearson([]float64{1, 2, 3}, []float64{1, 1, 1}); r != 0 {
+		t.Errorf("expected no correlation with a constant, got %f", r)
+	}
+}
+
+func TestCalibrate(t *testing.T) {
+	perfect := Calibrate(&exactEvaluator{search: NewSearcher(), factor: 1}, 1)
+	if math.Abs(perfect.Overall.Spearman-1) > 1e-9 || perfect.Overall.Agreement != 1 {
+		t.Errorf("expected exact values to be perfectly calibrated, got %+v", perfect.Overall)
+	}
+	for d, c := range perfect.ByDepth {
+		if (d%2 == 0) != (c.Moves > 0) {
+			t.Errorf("unexpected %d moves at depth %d for player 1", c.Moves, d)
+		}
+	}
+	backwards := Calibrate(&exactEvaluator{search: NewSearcher(), factor: -1}, 2)
+	if math.Abs(backwards.Overall.Spearman+1) > 1e-9 || backwards.Overall.Agreement != 0 {
+		t.Errorf("expected negated values to be perfectly wrong, got %+v", backwards.Overall)
+	}
+
+	// the fit finds each outcome's score, so asymmetric targets are no error
+	asym := Calibrate(&outcomeEvaluator{exactEvaluator{search: NewSearcher(), factor: 1}, 2, -9}, 1).Overall
+	if asym.Win != 2 || asym.Loss != -9 || asym.Draw != 0 || asym.MAE != 0 || asym.RMSE != 0 {
+		t.Errorf("expected a fit of 2 for a win and -9 for a loss with no error, got %+v", asym)
+	}
+	if perfect.Overall.Win <= 0 || perfect.Overall.Loss >= 0 || perfect.Overall.RMSE == 0 {
+		t.Errorf("expected exact values to fit a positive win, a negative loss and spread around them, got %+v", perfect.Overall)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCalibrationCSV(&buf, perfect); err != nil {
+		t.Fatal(err.Error())
+	}
+	// a header, depths 0, 2, 4, 6 and 8 and the overall row
+	if lines := strings.Count(buf.String(), "\n"); lines != 7 {
+		t.Errorf("expected 7 lines of csv, got %d:\n%s", lines, buf.String())
+	}
+	buf.Reset()
+	if err := WriteCalibrationPointsCSV(&buf, perfect, backwards); err != nil {
+		t.Fatal(err.Error())
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != len(perfect.Points)+len(backwards.Points)+1 {
+		t.Errorf("expected a line for every point, got %d", lines)
+	}
+}
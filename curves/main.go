@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"bigfunbrewing.com/tictactoe"
+)
+
+// point is a value of a series at an episode.
+type point struct {
+	x, y float64
+}
+
+type series struct {
+	name   string
+	color  string
+	points []point
+}
+
+// panel is one chart of the figure, its series share the axes.
+type panel struct {
+	title  string
+	series []series
+}
+
+const (
+	width       = 800.0
+	panelHeight = 220.0
+	left        = 70.0
+	right       = 110.0
+	top         = 30.0
+	bottom      = 30.0
+)
+
+func main() {
+	out := flag.String("o", "curves.svg", "path of the SVG file to write")
+	smooth := flag.Int("smooth", 10, "number of batches each point of the per batch curves is averaged over, 1 for none")
+	flag.Usage = func() {
+		fmt.Println("usage: curves [flags] metrics")
+		fmt.Println()
+		fmt.Println("metrics is a file written by TrainMlannPlayer -metrics, CSV if it ends in .csv and JSON lines otherwise")
+		fmt.Println()
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 || *smooth < 1 {
+		flag.Usage()
+		return
+	}
+	path := flag.Arg(0)
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	metrics, err := tictactoe.ReadMetrics(f, tictactoe.MetricsFormat(path))
+	f.Close()
+	if err != nil {
+		fmt.Println(path, err.Error())
+		os.Exit(1)
+	}
+	if len(metrics) == 0 {
+		fmt.Println(path, "has no metrics")
+		os.Exit(1)
+	}
+
+	// per batch values are noisy so they are averaged, evaluations are
+	// few and far between and drawn as they are
+	collect := func(name, color string, n int, value func(m tictactoe.Metrics) *float64) series {
+		s := series{name: name, color: color, points: make([]point, 0)}
+		for _, m := range metrics {
+			if v := value(m); v != nil {
+				s.points = append(s.points, point{x: float64(m.Episode), y: *v})
+			}
+		}
+		s.points = average(s.points, n)
+		return s
+	}
+	val := func(v float64) *float64 { return &v }
+	panels := []panel{
+		{title: "outcomes", series: []series{
+			collect("player 1", "#1f77b4", *smooth, func(m tictactoe.Metrics) *float64 { return val(m.One) }),
+			collect("player 2", "#d62728", *smooth, func(m tictactoe.Metrics) *float64 { return val(m.Two) }),
+			collect("draw", "#7f7f7f", *smooth, func(m tictactoe.Metrics) *float64 { return val(m.Draw) }),
+		}},
+		{title: "game length", series: []series{
+			collect("moves", "#2ca02c", *smooth, func(m tictactoe.Metrics) *float64 { return val(m.GameLen) }),
+		}},
+		{title: "training loss", series: []series{
+			collect("player 1", "#1f77b4", *smooth, func(m tictactoe.Metrics) *float64 { return m.Loss1 }),
+			collect("player 2", "#d62728", *smooth, func(m tictactoe.Metrics) *float64 { return m.Loss2 }),
+		}},
+		{title: "exploration", series: []series{
+			collect("player 1", "#1f77b4", 1, func(m tictactoe.Metrics) *float64 { return m.Epsilon1 }),
+			collect("player 2", "#d62728", 1, func(m tictactoe.Metrics) *float64 { return m.Epsilon2 }),
+		}},
+		{title: "optimal moves", series: []series{
+			collect("player 1", "#1f77b4", 1, func(m tictactoe.Metrics) *float64 { return m.Eval1 }),
+			collect("player 2", "#d62728", 1, func(m tictactoe.Metrics) *float64 { return m.Eval2 }),
+		}},
+	}
+
+	// leave out whatever the run did not record
+	drawn := make([]panel, 0)
+	for _, p := range panels {
+		kept := make([]series, 0)
+		for _, s := range p.series {
+			if len(s.points) > 0 {
+				kept = append(kept, s)
+			}
+		}
+		if len(kept) > 0 {
+			p.series = kept
+			drawn = append(drawn, p)
+		}
+	}
+
+	w, err := os.Create(*out)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	bw := bufio.NewWriter(w)
+	render(bw, drawn, float64(metrics[0].Episode), float64(metrics[len(metrics)-1].Episode))
+	if err := bw.Flush(); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	if err := w.Close(); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	fmt.Println("wrote", len(drawn), "charts of", len(metrics), "batches to", *out)
+}
+
+// average replaces every point with the mean of it and the n-1 points
+// before it.
+func average(points []point, n int) []point {
+	out := make([]point, len(points))
+	sum := 0.0
+	for i := range points {
+		sum += points[i].y
+		if i >= n {
+			sum -= points[i-n].y
+		}
+		k := math.Min(float64(i+1), float64(n))
+		out[i] = point{x: points[i].x, y: sum / k}
+	}
+	return out
+}
+
+// render writes the panels one above the other as an SVG, every panel
+// spanning episodes xmin to xmax.
+func render(w io.Writer, panels []panel, xmin, xmax float64) {
+	if xmax <= xmin {
+		xmax = xmin + 1
+	}
+	height := panelHeight * float64(len(panels))
+	fmt.Fprintf(w, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%.0f\" height=\"%.0f\" font-family=\"sans-serif\" font-size=\"11\">\n", width, height)
+	fmt.Fprintf(w, "<rect width=\"%.0f\" height=\"%.0f\" fill=\"white\"/>\n", width, height)
+	plotw := width - left - right
+	ploth := panelHeight - top - bottom
+	for n, p := range panels {
+		y0 := panelHeight * float64(n)
+		ymin, ymax := math.Inf(1), math.Inf(-1)
+		for _, s := range p.series {
+			for _, pt := range s.points {
+				ymin = math.Min(ymin, pt.y)
+				ymax = math.Max(ymax, pt.y)
+			}
+		}
+		if ymax-ymin < 1e-9 {
+			ymin, ymax = ymin-0.5, ymax+0.5
+		}
+		px := func(x float64) float64 { return left + (x-xmin)/(xmax-xmin)*plotw }
+		py := func(y float64) float64 { return y0 + top + (ymax-y)/(ymax-ymin)*ploth }
+
+		fmt.Fprintf(w, "<text x=\"%.1f\" y=\"%.1f\" font-size=\"13\" font-weight=\"bold\">%s</text>\n", left, y0+top-10, p.title)
+		for i := 0; i <= 4; i++ {
+			v := ymin + (ymax-ymin)*float64(i)/4
+			fmt.Fprintf(w, "<line x1=\"%.1f\" y1=\"%.1f\" x2=\"%.1f\" y2=\"%.1f\" stroke=\"#e0e0e0\"/>\n", left, py(v), left+plotw, py(v))
+			fmt.Fprintf(w, "<text x=\"%.1f\" y=\"%.1f\" text-anchor=\"end\">%.3g</text>\n", left-6, py(v)+4, v)
+		}
+		for i := 0; i <= 4; i++ {
+			v := xmin + (xmax-xmin)*float64(i)/4
+			fmt.Fprintf(w, "<text x=\"%.1f\" y=\"%.1f\" text-anchor=\"middle\">%.0f</text>\n", px(v), y0+top+ploth+16, v)
+		}
+		fmt.Fprintf(w, "<rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" fill=\"none\" stroke=\"#808080\"/>\n", left, y0+top, plotw, ploth)
+		for i, s := range p.series {
+			fmt.Fprintf(w, "<polyline fill=\"none\" stroke=\"%s\" stroke-width=\"1.5\" points=\"", s.color)
+			for _, pt := range s.points {
+				fmt.Fprintf(w, "%.1f,%.1f ", px(pt.x), py(pt.y))
+			}
+			fmt.Fprintln(w, "\"/>")
+			if len(s.points) == 1 {
+				fmt.Fprintf(w, "<circle cx=\"%.1f\" cy=\"%.1f\" r=\"2.5\" fill=\"%s\"/>\n", px(s.points[0].x), py(s.points[0].y), s.color)
+			}
+			ly := y0 + top + 12 + 16*float64(i)
+			fmt.Fprintf(w, "<line x1=\"%.1f\" y1=\"%.1f\" x2=\"%.1f\" y2=\"%.1f\" stroke=\"%s\" stroke-width=\"2\"/>\n", left+plotw+10, ly-4, left+plotw+28, ly-4, s.color)
+			fmt.Fprintf(w, "<text x=\"%.1f\" y=\"%.1f\">%s</text>\n", left+plotw+32, ly, s.name)
+		}
+	}
+	fmt.Fprintln(w, "</svg>")
+}
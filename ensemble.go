@@ -0,0 +1,200 @@
+package tictactoe
+
+import (
+	"fmt"
+)
+
+// Combine decides how an EnsemblePlayer merges the scores of its members.
+type Combine int
+
+const (
+	// CombineMean plays the move with the highest average score.
+	CombineMean Combine = iota
+	// CombineMax plays the move any single member scores highest.
+	CombineMax
+	// CombineVote lets each member vote for its own best move and plays the
+	// move with the most votes, ties going to the higher average score.
+	CombineVote
+)
+
+// ParseCombine converts the command line name of a combine rule into a
+// Combine. One of {mean, max, vote}.
+func ParseCombine(s string) (Combine, error) {
+	switch s {
+	case "mean", "":
+		return CombineMean, nil
+	case "max":
+		return CombineMax, nil
+	case "vote":
+		return CombineVote, nil
+	}
+	return CombineMean, fmt.Errorf("unknown combine rule %q", s)
+}
+
+// EnsemblePlayer plays with several trained value networks at once, for
+// example the networks from different training runs. Every candidate move
+// is scored by all the members and the scores are combined into one. The
+// members are only read, Train, Persist and Load leave them alone.
+//
+// The members should be trained with the same rewards, scores from
+// networks trained on different scales are not comparable under mean or max.
+type EnsemblePlayer struct {
+	pid     int
+	combine Combine
+	members []MoveEvaluator
+}
+
+// NewEnsemblePlayer returns an ensemble of members playing as pid. Every
+// member has to score moves, i.e. implement MoveEvaluator.
+func NewEnsemblePlayer(pid int, combine Combine, members ...Player) (*EnsemblePlayer, error) {
+	if len(members) == 0 {
+		return nil, fmt.Errorf("ensemble needs at least one member")
+	}
+	ep := &EnsemblePlayer{pid: pid, combine: combine, members: make([]MoveEvaluator, len(members))}
+	for i := range members {
+		me, ok := members[i].(MoveEvaluator)
+		if !ok {
+			return nil, fmt.Errorf("ensemble member %d (%T) does not score moves", i, members[i])
+		}
+		ep.members[i] = me
+	}
+	return ep, nil
+}
+
+// scores returns scores[m][i], the score member m gives moves[i].
+func (ep *EnsemblePlayer) scores(b Board, moves []*Move) [][]float64 {
+	out := make([][]float64, len(ep.members))
+	for m := range ep.members {
+		out[m] = make([]float64, len(moves))
+		for i := range moves {
+			out[m][i] = ep.members[m].EvalMove(b, moves[i])
+		}
+	}
+	return out
+}
+
+// combined merges the member scores of each move according to the combine
+// rule.
+func (ep *EnsemblePlayer) combined(scores [][]float64, n int) []float64 {
+	out := make([]float64, n)
+	for i := 0; i < n; i++ {
+		for m := range scores {
+			switch ep.combine {
+			case CombineMax:
+				if m == 0 || scores[m][i] > out[i] {
+					out[i] = scores[m][i]
+				}
+			default:
+				out[i] += scores[m][i] / float64(len(scores))
+			}
+		}
+	}
+	if ep.combine == CombineVote {
+		// the mean only breaks ties so it is mapped into (0, 1), below a
+		// single vote
+		scale := 1 + absMax(out)
+		for i := range out {
+			out[i] = (out[i]/scale + 1) / 2
+		}
+		for m := range scores {
+			out[argmax(scores[m])] += 1
+		}
+	}
+	return out
+}
+
+func argmax(values []float64) int {
+	best := 0
+	for i := range values {
+		if values[i] > values[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+func absMax(values []float64) float64 {
+	max := 0.0
+	for _, v := range values {
+		if v < 0 {
+			v = -v
+		}
+		if v > max {
+			max = v
+		}
+	}
+	return max
+}
+
+func (ep *EnsemblePlayer) Move(b Board) (mv *Move, err error) {
+	moves, err := ValidMoves(b, ep.pid)
+	if err != nil {
+		return nil, err
+	}
+	values := ep.combined(ep.scores(b, moves), len(moves))
+	return moves[argmax(values)], nil
+}
+
+// EvalMove returns the combined score of mv on b. Under CombineVote it is
+// the share of members whose best move is mv.
+func (ep *EnsemblePlayer) EvalMove(b Board, mv *Move) float64 {
+	moves, err := ValidMoves(b, mv.Pid)
+	if err != nil {
+		return 0
+	}
+	scores := ep.scores(b, moves)
+	pos := -1
+	for i := range moves {
+		if *moves[i] == *mv {
+			pos = i
+		}
+	}
+	if pos < 0 {
+		return 0
+	}
+	if ep.combine == CombineVote {
+		votes := 0.0
+		for m := range scores {
+			if argmax(scores[m]) == pos {
+				votes++
+			}
+		}
+		return votes / float64(len(scores))
+	}
+	return ep.combined(scores, len(moves))[pos]
+}
+
+func (ep *EnsemblePlayer) Train(games []*GamePlayed) {
+	//do nothing
+}
+
+func (ep *EnsemblePlayer) Persist(path string) error {
+	//do nothing
+	return nil
+}
+
+func (ep *EnsemblePlayer) Load(path string) error {
+	//do nothing
+	return nil
+}
+
+func (ep *EnsemblePlayer) Display(b Board) {
+	fmt.Println("         |     0     |     1     |     2     ")
+	fmt.Println("---------+-----------+-----------+-----------")
+	for i := 0; i < 3; i++ {
+		cells := make([]string, 3)
+		for j := 0; j < 3; j++ {
+			v, _ := b.Get(i, j)
+			cells[j] = convert(v)
+			if cells[j] == "" {
+				cells[j] = fmt.Sprintf("%9.5f", ep.EvalMove(b, &Move{Pid: ep.pid, Row: i, Col: j}))
+			}
+		}
+		fmt.Printf("    %d    | %s | %s | %s\n", i, cells[0], cells[1], cells[2])
+		if i < 2 {
+			fmt.Println("---------+---------+---------+---------")
+		} else {
+			fmt.Println()
+		}
+	}
+}
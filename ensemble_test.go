@@ -0,0 +1,84 @@
+package tictactoe
+
+import (
+	"testing"
+)
+
+// cellPlayer is a Player whose moves are scored by a fixed value per cell.
+type cellPlayer struct {
+	RandomPlayer
+	cellEvaluator
+}
+
+func newCellPlayer(values ...float64) *cellPlayer {
+	cp := &cellPlayer{RandomPlayer: RandomPlayer{pid: 1}}
+	copy(cp.cellEvaluator[:], values)
+	return cp
+}
+
+func TestEnsembleCombine(t *testing.T) {
+	b := NewBoard()
+	b.Reset()
+	// cell 0 is liked a little by two members, cell 1 a lot by one
+	members := []Player{
+		newCellPlayer(0.5, 0.0),
+		newCellPlayer(0.5, 0.0),
+		newCellPlayer(0.0, 3.0),
+	}
+	type test struct {
+		combine Combine
+		out     Move
+	}
+	tests := []test{
+		{combine: CombineMean, out: Move{Pid: 1, Row: 1, Col: 0}},
+		{combine: CombineMax, out: Move{Pid: 1, Row: 1, Col: 0}},
+		{combine: CombineVote, out: Move{Pid: 1, Row: 0, Col: 0}},
+	}
+	for i := range tests {
+		ep, err := NewEnsemblePlayer(1, tests[i].combine, members...)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		mv, err := ep.Move(b)
+		if err != nil {
+			t.Fatalf("test %d: %s", i, err.Error())
+		}
+		if *mv != tests[i].out {
+			t.Errorf("test %d: expected %v, got %v", i, tests[i].out, *mv)
+		}
+	}
+	ep, _ := NewEnsemblePlayer(1, CombineVote, members...)
+	if v := ep.EvalMove(b, &Move{Pid: 1, Row: 0, Col: 0}); v < 0.66 || v > 0.67 {
+		t.Errorf("expected two thirds of the votes, got %f", v)
+	}
+}
+
+func TestEnsembleVoteTieBreak(t *testing.T) {
+	b := NewBoard()
+	b.Reset()
+	// cells 0 and 2 get a vote each, cell 1 none but the best mean
+	members := []Player{
+		newCellPlayer(10, 9.9, -100, -1, -1, -1, -1, -1, -1),
+		newCellPlayer(-100, 9.9, 10, -1, -1, -1, -1, -1, -1),
+	}
+	ep, err := NewEnsemblePlayer(1, CombineVote, members...)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	mv, err := ep.Move(b)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if idx := loc(mv.Row, mv.Col); idx != 0 && idx != 2 {
+		t.Errorf("expected a move with a vote, got %v", *mv)
+	}
+}
+
+func TestEnsembleRejectsMembers(t *testing.T) {
+	if _, err := NewEnsemblePlayer(1, CombineMean, NewRandomPlayer(1)); err == nil {
+		t.Errorf("expected an error for a member that does not score moves")
+	}
+	if _, err := NewEnsemblePlayer(1, CombineMean); err == nil {
+		t.Errorf("expected an error for an empty ensemble")
+	}
+}
@@ -0,0 +1,161 @@
+package tictactoe
+
+import (
+	"fmt"
+)
+
+// reachable calls fn for every non-terminal position that can occur in a
+// game, each once, before the positions that follow it.
+func reachable(g grid, seen map[grid]bool, fn func(g grid)) {
+	if seen[g] || g.winner() != 0 {
+		return
+	}
+	seen[g] = true
+	fn(g)
+	toMove := g.toMove()
+	for _, idx := range g.empty() {
+		g[idx] = toMove
+		reachable(g, seen, fn)
+		g[idx] = 0
+	}
+}
+
+// PositionClass groups positions by what the player to move has to see.
+type PositionClass int
+
+const (
+	// MustWin positions have a move that wins on the spot.
+	MustWin PositionClass = iota
+	// MustBlock positions have no winning move but the opponent threatens
+	// to win next move.
+	MustBlock
+	// Fork positions have a move that makes two threats at once.
+	Fork
+	// BlockFork positions let the opponent fork next move.
+	BlockFork
+	// Quiet positions are the rest.
+	Quiet
+	numClasses
+)
+
+func (c PositionClass) String() string {
+	switch c {
+	case MustWin:
+		return "must-win"
+	case MustBlock:
+		return "must-block"
+	case Fork:
+		return "fork"
+	case BlockFork:
+		return "block fork"
+	case Quiet:
+		return "quiet"
+	}
+	return fmt.Sprintf("PositionClass(%d)", int(c))
+}
+
+// classify returns the class of g for pid, the first of the classes above
+// that applies.
+func classify(g grid, pid int) PositionClass {
+	switch {
+	case len(winningCells(g, pid)) > 0:
+		return MustWin
+	case len(winningCells(g, other(pid))) > 0:
+		return MustBlock
+	case len(forkCells(g, pid)) > 0:
+		return Fork
+	case len(forkCells(g, other(pid))) > 0:
+		return BlockFork
+	}
+	return Quiet
+}
+
+// Accuracy counts the positions a player was asked to move in and how many
+// times it found an optimal move.
+type Accuracy struct {
+	Positions int
+	Optimal   int
+}
+
+// Fraction is the share of positions with an optimal move, 0 when there
+// were none.
+func (a Accuracy) Fraction() float64 {
+	if a.Positions == 0 {
+		return 0
+	}
+	return float64(a.Optimal) / float64(a.Positions)
+}
+
+func (a *Accuracy) add(optimal bool) {
+	a.Positions++
+	if optimal {
+		a.Optimal++
+	}
+}
+
+// Mistake is a position where a player gave away a win or a draw.
+type Mistake struct {
+	Board  Board
+	Class  PositionClass
+	Played *Move
+	// Best is the outcome of the position with perfect play and Got the
+	// outcome after the move played, 1 a win, 0 a tie and -1 a loss for the
+	// player.
+	Best int
+	Got  int
+}
+
+// Evaluation is how often a player moves optimally over every reachable
+// position.
+type Evaluation struct {
+	Pid     int
+	Overall Accuracy
+	// ByMove is indexed by the number of moves made before the position,
+	// so player 1 moves at even and player 2 at odd indices.
+	ByMove [9]Accuracy
+	// ByClass is indexed by PositionClass.
+	ByClass  [numClasses]Accuracy
+	Mistakes []Mistake
+}
+
+// Evaluate asks p, playing as pid, for its move in every reachable position
+// where pid is to move. A move is optimal when it keeps the outcome perfect
+// play would get, a win stays a win and a draw a draw, however quickly. The
+// player should not be exploring.
+func Evaluate(p Player, pid int) (*Evaluation, error) {
+	s := NewSearcher()
+	out := &Evaluation{Pid: pid, Mistakes: make([]Mistake, 0)}
+	var err error
+	reachable(grid{}, make(map[grid]bool), func(g grid) {
+		if err != nil || g.toMove() != pid {
+			return
+		}
+		b := g.board()
+		mv, merr := p.Move(b)
+		if merr != nil {
+			err = fmt.Errorf("moving on %v: %w", g, merr)
+			return
+		}
+		idx := loc(mv.Row, mv.Col)
+		if mv.Pid != pid || g[idx] != 0 {
+			err = fmt.Errorf("invalid move %v on %v", *mv, g)
+			return
+		}
+		best := sign(s.Value(b))
+		after := g
+		after[idx] = pid
+		got := -sign(s.Value(after.board()))
+		optimal := got == best
+		class := classify(g, pid)
+		out.Overall.add(optimal)
+		out.ByMove[9-len(g.empty())].add(optimal)
+		out.ByClass[class].add(optimal)
+		if !optimal {
+			out.Mistakes = append(out.Mistakes, Mistake{Board: g.board(), Class: class, Played: mv, Best: best, Got: got})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
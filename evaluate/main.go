@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"bigfunbrewing.com/tictactoe"
+)
+
+// outcomes names the outcomes of a position for the player to move.
+var outcomes = map[int]string{1: "win", 0: "draw", -1: "loss"}
+
+func main() {
+	pid := flag.Int("pid", 0, "evaluate the player as player 1 or 2, 0 for every side it can play")
+	mistakes := flag.Int("mistakes", 0, "number of positions with a non optimal move to show for each player")
+	flag.Usage = func() {
+		fmt.Println("usage: evaluate [flags] spec ...")
+		fmt.Println()
+		fmt.Println("each spec is a player type optionally followed by a colon and a model path or argument, e.g.")
+		fmt.Println("mlannplayer:player1.net mlannplayer:player{pid}.net gruplayer:gplayer2.net heuristicplayer")
+		fmt.Println()
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 || *pid < 0 || *pid > 2 {
+		flag.Usage()
+		return
+	}
+	pids := []int{1, 2}
+	if *pid != 0 {
+		pids = []int{*pid}
+	}
+
+	for _, spec := range flag.Args() {
+		for _, id := range pids {
+			p, err := tictactoe.NewPlayerFromSpec(spec, id)
+			if *pid == 0 && tictactoe.WrongSide(err) {
+				// a model trained for the other side only plays that side
+				continue
+			}
+			if err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+			e, err := tictactoe.Evaluate(p, id)
+			if err != nil {
+				fmt.Println(spec, err.Error())
+				os.Exit(1)
+			}
+			report(spec, e, *mistakes)
+		}
+	}
+}
+
+// report prints the accuracy of the player overall, by move and by class of
+// position, followed by up to n of its mistakes.
+func report(spec string, e *tictactoe.Evaluation, n int) {
+	fmt.Printf("%s as player %d: %d of %d positions optimal, %.3f\n", spec, e.Pid, e.Overall.Optimal, e.Overall.Positions, e.Overall.Fraction())
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "move\tpositions\toptimal\taccuracy\t")
+	for i, a := range e.ByMove {
+		if a.Positions > 0 {
+			fmt.Fprintf(w, "%d\t%d\t%d\t%.3f\t\n", i+1, a.Positions, a.Optimal, a.Fraction())
+		}
+	}
+	fmt.Fprintln(w, "\t\t\t\t")
+	fmt.Fprintln(w, "class\tpositions\toptimal\taccuracy\t")
+	for c, a := range e.ByClass {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.3f\t\n", tictactoe.PositionClass(c), a.Positions, a.Optimal, a.Fraction())
+	}
+	w.Flush()
+	for i, m := range e.Mistakes {
+		if i == n {
+			break
+		}
+		fmt.Printf("\n%s position, played row %d col %d turning a %s into a %s\n", m.Class, m.Played.Row, m.Played.Col, outcomes[m.Best], outcomes[m.Got])
+		m.Board.Display()
+	}
+	fmt.Println()
+}
@@ -0,0 +1,69 @@
+package tictactoe
+
+import (
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	type test struct {
+		g   grid
+		pid int
+		out PositionClass
+	}
+	// grids are indexed by loc, column by column
+	tests := []test{
+		// player 1 completes the top row
+		{g: grid{1, 2, 0, 1, 2, 0, 0, 0, 0}, pid: 1, out: MustWin},
+		// player 2 completes the middle row
+		{g: grid{1, 2, 0, 0, 2, 0, 1, 0, 0}, pid: 2, out: MustWin},
+		// player 2 threatens the middle column
+		{g: grid{1, 0, 0, 2, 2, 0, 0, 0, 1}, pid: 1, out: MustBlock},
+		{g: grid{1, 0, 0, 0, 2, 0, 0, 0, 0}, pid: 1, out: Quiet},
+		// player 1 holds the top corners and forks with the center or bottom right
+		{g: grid{1, 2, 0, 2, 0, 0, 1, 0, 0}, pid: 1, out: Fork},
+		{g: grid{1, 0, 0, 2, 0, 0, 1, 0, 0}, pid: 2, out: BlockFork},
+	}
+	for i := range tests {
+		if out := classify(tests[i].g, tests[i].pid); out != tests[i].out {
+			t.Errorf("test %d: expected %s, got %s", i, tests[i].out, out)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	total := 0
+	for pid := 1; pid <= 2; pid++ {
+		e, err := Evaluate(NewMinimaxPlayer(pid, TieRandom), pid)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if e.Overall.Optimal != e.Overall.Positions || len(e.Mistakes) != 0 {
+			t.Errorf("player %d: expected minimax to always move optimally, %d of %d", pid, e.Overall.Optimal, e.Overall.Positions)
+		}
+		for n := range e.ByMove {
+			if (n%2 == pid-1) != (e.ByMove[n].Positions > 0) {
+				t.Errorf("player %d: unexpected %d positions after %d moves", pid, e.ByMove[n].Positions, n)
+			}
+		}
+		total += e.Overall.Positions
+	}
+	if total != 4520 {
+		t.Errorf("expected 4520 reachable non-terminal positions, found %d", total)
+	}
+
+	e, err := Evaluate(NewRandomPlayer(1), 1)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if e.Overall.Fraction() > 0.9 || len(e.Mistakes) != e.Overall.Positions-e.Overall.Optimal {
+		t.Errorf("expected a random player to make mistakes, %d of %d optimal", e.Overall.Optimal, e.Overall.Positions)
+	}
+	for _, m := range e.Mistakes {
+		if m.Got >= m.Best {
+			t.Errorf("mistake %v did not make the outcome worse", m)
+		}
+	}
+	if f := e.ByClass[MustWin].Fraction(); f == 1 {
+		t.Errorf("expected a random player to miss some wins")
+	}
+}
@@ -0,0 +1,154 @@
+package tictactoe
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Exploit is what a best-response opponent can do against a deterministic
+// player.
+type Exploit struct {
+	Pid int
+	// Positions is the number of positions the player has to move in
+	// against every possible opponent.
+	Positions int
+	// Loses is true when an opponent can force the player to lose.
+	Loses bool
+	// Line is the shortest game the opponent can force the player to lose,
+	// the moves of both players from the empty board.
+	Line []*Move
+	// Losing holds the positions the player moves in from which the
+	// opponent can force a win, in the order of the moves made so far.
+	Losing []Board
+}
+
+// response is the outcome of a position when the opponent plays the best
+// response, value 1 if the opponent wins, 0 for a tie and -1 if the player
+// wins, and plies the number of moves until the game ends.
+type response struct {
+	value int
+	plies int
+	// idx is the cell the player or the opponent moves to next
+	idx int
+}
+
+// exploiter searches every game the player can be drawn into.
+type exploiter struct {
+	p     Player
+	pid   int
+	memo  map[grid]response
+	moves map[grid]int
+}
+
+// BestResponse searches every reply to every move p makes as pid for the
+// opponent moves that do p the most harm. p has to be deterministic, e.g. a
+// network player that does not explore, it is asked for its move in every
+// position twice and an error is returned if the answers differ.
+func BestResponse(p Player, pid int) (*Exploit, error) {
+	ex := &exploiter{p: p, pid: pid, memo: make(map[grid]response), moves: make(map[grid]int)}
+	start, err := ex.search(grid{})
+	if err != nil {
+		return nil, err
+	}
+	out := &Exploit{Pid: pid, Loses: start.value == 1, Losing: make([]Board, 0)}
+	if out.Loses {
+		for g := (grid{}); g.winner() == 0; {
+			r := ex.memo[g]
+			toMove := g.toMove()
+			out.Line = append(out.Line, g.move(toMove, r.idx))
+			g[r.idx] = toMove
+		}
+	}
+	losing := make([]grid, 0)
+	for g, r := range ex.memo {
+		if g.winner() != 0 || g.toMove() != pid {
+			continue
+		}
+		out.Positions++
+		if r.value == 1 {
+			losing = append(losing, g)
+		}
+	}
+	sort.Slice(losing, func(i, j int) bool {
+		ni, nj := len(losing[i].empty()), len(losing[j].empty())
+		if ni != nj {
+			return ni > nj
+		}
+		return losing[i].key() < losing[j].key()
+	})
+	for _, g := range losing {
+		out.Losing = append(out.Losing, g.board())
+	}
+	return out, nil
+}
+
+// move returns the cell the player moves to on g.
+func (ex *exploiter) move(g grid) (int, error) {
+	if idx, ok := ex.moves[g]; ok {
+		return idx, nil
+	}
+	cells := [2]int{}
+	for i := range cells {
+		mv, err := ex.p.Move(g.board())
+		if err != nil {
+			return -1, fmt.Errorf("moving on %v: %w", g, err)
+		}
+		cells[i] = loc(mv.Row, mv.Col)
+		if mv.Pid != ex.pid || g[cells[i]] != 0 {
+			return -1, fmt.Errorf("invalid move %v on %v", *mv, g)
+		}
+	}
+	if cells[0] != cells[1] {
+		return -1, fmt.Errorf("player is not deterministic, it moved to %d and then %d on %v", cells[0], cells[1], g)
+	}
+	ex.moves[g] = cells[0]
+	return cells[0], nil
+}
+
+// search returns the outcome of g with the opponent playing its best
+// response, preferring the quickest win and the slowest loss.
+func (ex *exploiter) search(g grid) (response, error) {
+	if r, ok := ex.memo[g]; ok {
+		return r, nil
+	}
+	var r response
+	switch w := g.winner(); {
+	case w == -1:
+		r = response{value: 0, idx: -1}
+	case w == ex.pid:
+		r = response{value: -1, idx: -1}
+	case w != 0:
+		r = response{value: 1, idx: -1}
+	case g.toMove() == ex.pid:
+		idx, err := ex.move(g)
+		if err != nil {
+			return r, err
+		}
+		g[idx] = ex.pid
+		next, err := ex.search(g)
+		g[idx] = 0
+		if err != nil {
+			return r, err
+		}
+		r = response{value: next.value, plies: next.plies + 1, idx: idx}
+	default:
+		opp := other(ex.pid)
+		r.idx = -1
+		for _, idx := range g.empty() {
+			g[idx] = opp
+			next, err := ex.search(g)
+			g[idx] = 0
+			if err != nil {
+				return r, err
+			}
+			next.plies++
+			if r.idx == -1 || next.value > r.value ||
+				(next.value == r.value && next.value > 0 && next.plies < r.plies) ||
+				(next.value == r.value && next.value < 0 && next.plies > r.plies) {
+				r = response{value: next.value, plies: next.plies, idx: idx}
+			}
+		}
+	}
+	ex.memo[g] = r
+	return r, nil
+}
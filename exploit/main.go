@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"bigfunbrewing.com/tictactoe"
+)
+
+func main() {
+	pid := flag.Int("pid", 0, "analyse the player as player 1 or 2, 0 for every side it can play")
+	losing := flag.Int("losing", -1, "number of losing positions to show for each player, -1 for all")
+	flag.Usage = func() {
+		fmt.Println("usage: exploit [flags] spec ...")
+		fmt.Println()
+		fmt.Println("each spec is a player type optionally followed by a colon and a model path or argument, e.g.")
+		fmt.Println("mlannplayer:player1.net mlannplayer:player{pid}.net gruplayer:gplayer2.net heuristicplayer")
+		fmt.Println()
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 || *pid < 0 || *pid > 2 {
+		flag.Usage()
+		return
+	}
+	pids := []int{1, 2}
+	if *pid != 0 {
+		pids = []int{*pid}
+	}
+
+	beaten := false
+	for _, spec := range flag.Args() {
+		for _, id := range pids {
+			p, err := tictactoe.NewPlayerFromSpec(spec, id)
+			if *pid == 0 && tictactoe.WrongSide(err) {
+				// a model trained for the other side only plays that side
+				continue
+			}
+			if err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+			ex, err := tictactoe.BestResponse(p, id)
+			if err != nil {
+				fmt.Println(spec, err.Error())
+				os.Exit(1)
+			}
+			report(spec, ex, *losing)
+			beaten = beaten || ex.Loses
+		}
+	}
+	// let scripts refuse to ship a beatable player
+	if beaten {
+		os.Exit(2)
+	}
+}
+
+// report prints whether the player can be beaten, the quickest way to beat
+// it and up to n of the positions it loses from.
+func report(spec string, ex *tictactoe.Exploit, n int) {
+	if !ex.Loses {
+		fmt.Printf("%s as player %d cannot be beaten, it moves in %d positions against every opponent\n\n", spec, ex.Pid, ex.Positions)
+		return
+	}
+	fmt.Printf("%s as player %d can be forced to lose from %d of the %d positions it moves in\n", spec, ex.Pid, len(ex.Losing), ex.Positions)
+	fmt.Printf("shortest forced loss, %d moves:", len(ex.Line))
+	b := tictactoe.NewBoard()
+	b.Reset()
+	for _, mv := range ex.Line {
+		fmt.Printf(" %d:(%d,%d)", mv.Pid, mv.Row, mv.Col)
+		b.Move(mv)
+	}
+	fmt.Println()
+	b.Display()
+	for i, l := range ex.Losing {
+		if i == n {
+			break
+		}
+		fmt.Printf("losing position %d\n", i+1)
+		l.Display()
+	}
+}
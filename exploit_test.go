@@ -0,0 +1,71 @@
+package tictactoe
+
+import (
+	"testing"
+)
+
+// firstPlayer always plays the first empty cell.
+type firstPlayer struct {
+	RandomPlayer
+}
+
+func (fp *firstPlayer) Move(b Board) (*Move, error) {
+	moves, err := ValidMoves(b, fp.pid)
+	if err != nil {
+		return nil, err
+	}
+	return moves[0], nil
+}
+
+func TestBestResponse(t *testing.T) {
+	for pid := 1; pid <= 2; pid++ {
+		ex, err := BestResponse(NewMinimaxPlayer(pid, TieFirst), pid)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if ex.Loses || len(ex.Line) != 0 || len(ex.Losing) != 0 {
+			t.Errorf("player %d: expected minimax to be unbeatable, got %+v", pid, ex)
+		}
+		if ex.Positions == 0 {
+			t.Errorf("player %d: expected minimax to have moved", pid)
+		}
+	}
+
+	for pid := 1; pid <= 2; pid++ {
+		fp := &firstPlayer{RandomPlayer{pid: pid}}
+		ex, err := BestResponse(fp, pid)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if !ex.Loses || len(ex.Losing) == 0 {
+			t.Fatalf("player %d: expected first cell play to lose", pid)
+		}
+		b := NewBoard()
+		b.Reset()
+		for i, mv := range ex.Line {
+			if mv.Pid == pid {
+				if want, _ := fp.Move(b); *want != *mv {
+					t.Errorf("player %d: move %d of the line is %v, the player plays %v", pid, i, *mv, *want)
+				}
+			}
+			if err := b.Move(mv); err != nil {
+				t.Fatal(err.Error())
+			}
+		}
+		if w := b.GameOver(); w != other(pid) {
+			t.Errorf("player %d: expected the line to end in a loss, got %d", pid, w)
+		}
+		// the opponent needs three moves to win, and when the player moves
+		// first it moves as often
+		if want := 5 + (2 - pid); len(ex.Line) != want {
+			t.Errorf("player %d: expected a %d move line, got %d", pid, want, len(ex.Line))
+		}
+		if pid == 1 && gridOf(ex.Losing[0]) != (grid{}) {
+			t.Errorf("expected the empty board to be lost for first cell play")
+		}
+	}
+
+	if _, err := BestResponse(NewRandomPlayer(1), 1); err == nil {
+		t.Errorf("expected an error for a random player")
+	}
+}
@@ -0,0 +1,155 @@
+package tictactoe
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// Explorer decides when a learning player tries a move other than the one
+// its network likes best, and which.
+type Explorer interface {
+	// Choose returns the index into moves of the move to explore on b, or -1
+	// to leave the choice to the player. eval scores the moves.
+	Choose(b Board, moves []*Move, eval MoveEvaluator) int
+	// Rate returns how much the explorer explores, epsilon, temperature or
+	// exploration constant depending on the explorer.
+	Rate() float64
+	// SetRate changes how much the explorer explores.
+	SetRate(rate float64)
+}
+
+// ParseExplorer converts the command line name of an exploration strategy
+// into an Explorer with the given rate. One of {epsilon, boltzmann, ucb}.
+func ParseExplorer(s string, rate float64) (Explorer, error) {
+	switch s {
+	case "epsilon", "":
+		return NewEpsilonGreedy(rate), nil
+	case "boltzmann":
+		return NewBoltzmann(rate), nil
+	case "ucb":
+		return NewUCBExplorer(rate), nil
+	}
+	return nil, fmt.Errorf("unknown exploration strategy %q", s)
+}
+
+// EpsilonGreedy plays a uniformly random move with probability epsilon.
+type EpsilonGreedy struct {
+	epsilon float64
+}
+
+func NewEpsilonGreedy(epsilon float64) *EpsilonGreedy {
+	return &EpsilonGreedy{epsilon: epsilon}
+}
+
+func (eg *EpsilonGreedy) Choose(b Board, moves []*Move, eval MoveEvaluator) int {
+	if rand.Float64() < eg.epsilon {
+		return rand.Intn(len(moves))
+	}
+	return -1
+}
+
+func (eg *EpsilonGreedy) Rate() float64 {
+	return eg.epsilon
+}
+
+func (eg *EpsilonGreedy) SetRate(rate float64) {
+	eg.epsilon = rate
+}
+
+// Boltzmann samples every move from a softmax over the network's scores, so
+// moves that score nearly as well as the best are tried far more often than
+// bad ones. The higher the temperature the closer it is to random play, a
+// temperature of 0 never explores.
+type Boltzmann struct {
+	temperature float64
+}
+
+func NewBoltzmann(temperature float64) *Boltzmann {
+	return &Boltzmann{temperature: temperature}
+}
+
+func (bz *Boltzmann) Choose(b Board, moves []*Move, eval MoveEvaluator) int {
+	if bz.temperature <= 0 {
+		return -1
+	}
+	scores := make([]float64, len(moves))
+	for i := range moves {
+		scores[i] = eval.EvalMove(b, moves[i])
+	}
+	return sampleSoftmax(scores, bz.temperature)
+}
+
+func (bz *Boltzmann) Rate() float64 {
+	return bz.temperature
+}
+
+func (bz *Boltzmann) SetRate(rate float64) {
+	bz.temperature = rate
+}
+
+// UCBExplorer counts how often each board and each move from it has been
+// played and plays the move maximizing
+//
+//	score + c*sqrt(ln(N(s)+1)/(N(s,a)+1))
+//
+// so moves it has rarely tried get a bonus that shrinks as they are played.
+// A move is counted by the board it leads to, boards reached by different
+// orders of moves share their count. A c of 0 never explores. It is safe to
+// use from several goroutines, which then share their counts.
+//
+// With c above 0 it picks every move itself, a player's lookahead or
+// rollouts are never used while it explores.
+type UCBExplorer struct {
+	c      float64
+	mu     sync.Mutex
+	counts map[int]int
+}
+
+func NewUCBExplorer(c float64) *UCBExplorer {
+	return &UCBExplorer{c: c, counts: make(map[int]int)}
+}
+
+func (ue *UCBExplorer) Choose(b Board, moves []*Move, eval MoveEvaluator) int {
+	c := ue.Rate()
+	if c <= 0 {
+		return -1
+	}
+	g := gridOf(b)
+	keys := make([]int, len(moves))
+	for i := range moves {
+		ng := g
+		ng[loc(moves[i].Row, moves[i].Col)] = moves[i].Pid
+		keys[i] = ng.key()
+	}
+	scores := make([]float64, len(moves))
+	for i := range moves {
+		scores[i] = eval.EvalMove(b, moves[i])
+	}
+	ue.mu.Lock()
+	defer ue.mu.Unlock()
+	n := float64(ue.counts[g.key()])
+	best, bv := 0, math.Inf(-1)
+	for i := range moves {
+		v := scores[i] + c*math.Sqrt(math.Log(n+1)/float64(ue.counts[keys[i]]+1))
+		if v > bv {
+			best, bv = i, v
+		}
+	}
+	ue.counts[g.key()]++
+	ue.counts[keys[best]]++
+	return best
+}
+
+func (ue *UCBExplorer) Rate() float64 {
+	ue.mu.Lock()
+	defer ue.mu.Unlock()
+	return ue.c
+}
+
+func (ue *UCBExplorer) SetRate(rate float64) {
+	ue.mu.Lock()
+	defer ue.mu.Unlock()
+	ue.c = rate
+}
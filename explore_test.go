@@ -0,0 +1,77 @@
+package tictactoe
+
+import (
+	"testing"
+)
+
+func TestParseExplorer(t *testing.T) {
+	for _, s := range []string{"epsilon", "boltzmann", "ucb"} {
+		e, err := ParseExplorer(s, 0.5)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if e.Rate() != 0.5 {
+			t.Errorf("%s: expected rate 0.5, got %f", s, e.Rate())
+		}
+	}
+	if _, err := ParseExplorer("softmax", 0.5); err == nil {
+		t.Errorf("expected an error for an unknown strategy")
+	}
+}
+
+func TestExplorerRates(t *testing.T) {
+	b := NewBoard()
+	b.Reset()
+	moves, _ := ValidMoves(b, 1)
+	var eval cellEvaluator
+	eval[loc(1, 1)] = 10
+	for _, e := range []Explorer{NewEpsilonGreedy(0), NewBoltzmann(0), NewUCBExplorer(0)} {
+		if i := e.Choose(b, moves, eval); i != -1 {
+			t.Errorf("%T explored with a rate of 0", e)
+		}
+	}
+	for i := 0; i < 20; i++ {
+		if k := NewEpsilonGreedy(1).Choose(b, moves, eval); k < 0 || k >= len(moves) {
+			t.Errorf("epsilon 1 did not explore, got %d", k)
+		}
+	}
+	// a cold softmax plays the best move, a hot one spreads its moves out
+	counts := make(map[int]int)
+	for i := 0; i < 200; i++ {
+		counts[NewBoltzmann(0.01).Choose(b, moves, eval)]++
+	}
+	if len(counts) != 1 || *moves[firstKey(counts)] != (Move{Pid: 1, Row: 1, Col: 1}) {
+		t.Errorf("expected a cold softmax to always play the center, got %v", counts)
+	}
+	counts = make(map[int]int)
+	hot := NewBoltzmann(100)
+	for i := 0; i < 200; i++ {
+		counts[hot.Choose(b, moves, eval)]++
+	}
+	if len(counts) < 5 {
+		t.Errorf("expected a hot softmax to try most moves, got %v", counts)
+	}
+}
+
+func firstKey(m map[int]int) int {
+	for k := range m {
+		return k
+	}
+	return -1
+}
+
+func TestUCBTriesEveryMove(t *testing.T) {
+	b := NewBoard()
+	b.Reset()
+	moves, _ := ValidMoves(b, 1)
+	var eval cellEvaluator
+	eval[loc(1, 1)] = 1
+	ue := NewUCBExplorer(2)
+	tried := make(map[int]bool)
+	for i := 0; i < 100; i++ {
+		tried[ue.Choose(b, moves, eval)] = true
+	}
+	if len(tried) != len(moves) {
+		t.Errorf("expected ucb to try all %d moves, tried %d", len(moves), len(tried))
+	}
+}
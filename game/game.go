@@ -1,8 +1,12 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io/fs"
+	"os"
+	"strings"
 
 	"bigfunbrewing.com/tictactoe"
 )
@@ -57,14 +61,58 @@ func episode(player1, player2 tictactoe.Player) (g *tictactoe.GamePlayed, outcom
 	return
 }
 
+// ensemble builds an ensemble player for pid from a comma separated list of
+// member networks. Each member is a path, optionally prefixed with the player
+// type, e.g. "run1.net,gruplayer:run2.net". Members without a type are
+// mlannplayers.
+func ensemble(pid int, members string, combine tictactoe.Combine, epsilon, gamma float64) (*tictactoe.EnsemblePlayer, error) {
+	players := make([]tictactoe.Player, 0)
+	for _, member := range strings.Split(members, ",") {
+		kind, path := "mlannplayer", member
+		if i := strings.Index(member, ":"); i >= 0 {
+			kind, path = member[:i], member[i+1:]
+		}
+		var p tictactoe.Player
+		switch kind {
+		case "mlannplayer":
+			p = tictactoe.NewMlannPlayer(pid, epsilon, gamma)
+		case "gruplayer":
+			p = tictactoe.NewGruPlayer(pid, epsilon)
+		default:
+			return nil, fmt.Errorf("unknown ensemble member type %q", kind)
+		}
+		if err := p.Load(path); err != nil {
+			return nil, err
+		}
+		players = append(players, p)
+	}
+	return tictactoe.NewEnsemblePlayer(pid, combine, players...)
+}
+
 func main() {
 	net1path := flag.String("net1", "", "path to the network to play against")
 	net2path := flag.String("net2", "", "path to the network to play against")
-	splayer1 := flag.String("player1", "", "choose the type for player 1 (randoplayer|mlannplayer|gruplayer)")
-	splayer2 := flag.String("player2", "", "choose the type for player 2 (randoplayer|mlannplayer|gruplayer)")
+	splayer1 := flag.String("player1", "", "choose the type for player 1 (randoplayer|mlannplayer|gruplayer|minimaxplayer|heuristicplayer|skillplayer|ensembleplayer|mctsplayer|alphazeroplayer|qtableplayer|policyplayer|humanplayer)")
+	splayer2 := flag.String("player2", "", "choose the type for player 2 (randoplayer|mlannplayer|gruplayer|minimaxplayer|heuristicplayer|skillplayer|ensembleplayer|mctsplayer|alphazeroplayer|qtableplayer|policyplayer|humanplayer)")
 	episodes := flag.Int("games", 10, "number of games to play")
 	gamma := flag.Float64("gamma", 0.9, "gamma is the discount rate on future rewards")
 	epsilon := flag.Float64("epsilon", 0.01, "epsilon is the exploration rate for NN players")
+	tiebreak := flag.String("tiebreak", "random", "how minimax players choose among equally good moves (first|random|fastest)")
+	playouts := flag.Int("playouts", 1000, "number of playouts per move for MCTS players, 0 to only use -budget")
+	budget := flag.Duration("budget", 0, "time limit per move for MCTS players, 0 to only use -playouts")
+	uct := flag.Float64("uct", 1.4, "UCT exploration constant for MCTS players, PUCT constant for alphazero players")
+	rollouts := flag.Int("rollouts", 0, "number of games gru players simulate from each candidate move, 0 scores each move once")
+	depth := flag.Int("depth", 0, "rounds of lookahead for mlann and gru players, 0 picks the best move greedily")
+	n1 := flag.Int("n1", 3, "number of own moves expanded at each round of lookahead")
+	n2 := flag.Int("n2", 3, "number of opponent replies expanded for each move during lookahead")
+	opponent := flag.String("opponent", "min", "how lookahead expects the opponent to reply (min|self)")
+	baseline := flag.Bool("baseline", true, "whether policy player networks were trained with a critic baseline")
+	temperature := flag.Float64("temperature", 0, "move selection temperature for alphazero players, 0 always plays the most visited move")
+	members1 := flag.String("members1", "", "comma separated networks for an ensemble player 1, each optionally prefixed by mlannplayer: or gruplayer:")
+	members2 := flag.String("members2", "", "comma separated networks for an ensemble player 2, each optionally prefixed by mlannplayer: or gruplayer:")
+	combine := flag.String("combine", "mean", "how ensemble players combine their members' scores (mean|max|vote)")
+	skill := flag.String("skill", "perfect", "how often skill players play the optimal move (easy|medium|hard|perfect) or a number in [0,1]")
+	rollout := flag.String("rollout", "randoplayer", "player used to finish MCTS playouts (randoplayer|minimaxplayer|heuristicplayer)")
 	flag.Parse()
 
 	fmt.Println(*net1path, *net2path, *splayer1, *splayer2, *episodes, *gamma, *epsilon)
@@ -72,11 +120,57 @@ func main() {
 		flag.PrintDefaults()
 		return
 	}
-	if (*splayer1 == "gruplayer" || *splayer1 == "mlannplayer") && *net1path == "" {
+	if (*splayer1 == "ensembleplayer" && *members1 == "") || (*splayer2 == "ensembleplayer" && *members2 == "") {
+		flag.PrintDefaults()
+		return
+	}
+	if (*splayer1 == "gruplayer" || *splayer1 == "mlannplayer" || *splayer1 == "alphazeroplayer" || *splayer1 == "qtableplayer" || *splayer1 == "policyplayer") && *net1path == "" {
+		flag.PrintDefaults()
+		return
+	}
+	if (*splayer2 == "gruplayer" || *splayer2 == "mlannplayer" || *splayer2 == "alphazeroplayer" || *splayer2 == "qtableplayer" || *splayer2 == "policyplayer") && *net2path == "" {
+		flag.PrintDefaults()
+		return
+	}
+
+	tie, err := tictactoe.ParseTieBreak(*tiebreak)
+	if err != nil {
+		fmt.Println(err.Error())
+		flag.PrintDefaults()
+		return
+	}
+
+	level, err := tictactoe.ParseSkill(*skill)
+	if err != nil {
+		fmt.Println(err.Error())
+		flag.PrintDefaults()
+		return
+	}
+
+	rule, err := tictactoe.ParseCombine(*combine)
+	if err != nil {
+		fmt.Println(err.Error())
+		flag.PrintDefaults()
+		return
+	}
+
+	model, err := tictactoe.ParseOpponentModel(*opponent)
+	if err != nil {
+		fmt.Println(err.Error())
 		flag.PrintDefaults()
 		return
 	}
-	if (*splayer2 == "gruplayer" || *splayer2 == "mlannplayer") && *net2path == "" {
+
+	var policy tictactoe.RolloutPolicy
+	switch *rollout {
+	case "randoplayer":
+		policy = tictactoe.RandomRollout
+	case "minimaxplayer":
+		policy = func(pid int) tictactoe.Player { return tictactoe.NewMinimaxPlayer(pid, tie) }
+	case "heuristicplayer":
+		policy = func(pid int) tictactoe.Player { return tictactoe.NewHeuristicPlayer(pid) }
+	default:
+		fmt.Println("unknown rollout player", *rollout)
 		flag.PrintDefaults()
 		return
 	}
@@ -91,27 +185,122 @@ func main() {
 	case "randoplayer":
 		player1 = tictactoe.NewRandomPlayer(1)
 	case "mlannplayer":
-		player1 = tictactoe.NewMlannPlayer(1, *net1path, *epsilon, *gamma)
+		player1 = tictactoe.NewMlannPlayer(1, *epsilon, *gamma)
 	case "gruplayer":
-		player1 = tictactoe.NewGruPlayer(1, *net1path, *epsilon)
+		player1 = tictactoe.NewGruPlayer(1, *epsilon)
 	case "humanplayer":
 		player1 = tictactoe.NewHumanPlayer(1)
+	case "minimaxplayer":
+		player1 = tictactoe.NewMinimaxPlayer(1, tie)
+	case "heuristicplayer":
+		player1 = tictactoe.NewHeuristicPlayer(1)
+	case "skillplayer":
+		player1 = tictactoe.NewSkillPlayer(1, level, tictactoe.NewMinimaxPlayer(1, tie))
+	case "ensembleplayer":
+		player1, err = ensemble(1, *members1, rule, *epsilon, *gamma)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+	case "mctsplayer":
+		player1 = tictactoe.NewMCTSPlayer(1, *playouts, *budget, *uct, policy)
+	case "alphazeroplayer":
+		player1 = tictactoe.NewAlphaZeroPlayer(1, *playouts, *uct, *temperature)
+	case "qtableplayer":
+		player1 = tictactoe.NewQTablePlayer(1, *epsilon, 0.5, *gamma)
+	case "policyplayer":
+		player1 = tictactoe.NewPolicyPlayer(1, *gamma, *baseline)
 	}
 
 	switch *splayer2 {
 	case "randoplayer":
 		player2 = tictactoe.NewRandomPlayer(2)
 	case "mlannplayer":
-		player2 = tictactoe.NewMlannPlayer(2, *net2path, *epsilon, *gamma)
+		player2 = tictactoe.NewMlannPlayer(2, *epsilon, *gamma)
 	case "gruplayer":
-		player2 = tictactoe.NewGruPlayer(2, *net2path, *epsilon)
+		player2 = tictactoe.NewGruPlayer(2, *epsilon)
 	case "humanplayer":
 		player2 = tictactoe.NewHumanPlayer(2)
+	case "minimaxplayer":
+		player2 = tictactoe.NewMinimaxPlayer(2, tie)
+	case "heuristicplayer":
+		player2 = tictactoe.NewHeuristicPlayer(2)
+	case "skillplayer":
+		player2 = tictactoe.NewSkillPlayer(2, level, tictactoe.NewMinimaxPlayer(2, tie))
+	case "ensembleplayer":
+		player2, err = ensemble(2, *members2, rule, *epsilon, *gamma)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+	case "mctsplayer":
+		player2 = tictactoe.NewMCTSPlayer(2, *playouts, *budget, *uct, policy)
+	case "alphazeroplayer":
+		player2 = tictactoe.NewAlphaZeroPlayer(2, *playouts, *uct, *temperature)
+	case "qtableplayer":
+		player2 = tictactoe.NewQTablePlayer(2, *epsilon, 0.5, *gamma)
+	case "policyplayer":
+		player2 = tictactoe.NewPolicyPlayer(2, *gamma, *baseline)
+	}
+
+	if err := load(player1, *net1path); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	if err := load(player2, *net2path); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	for _, p := range []tictactoe.Player{player1, player2} {
+		if gp, ok := p.(*tictactoe.GruPlayer); ok {
+			gp.SetRollouts(*rollouts)
+		}
+	}
+
+	if *depth > 0 {
+		la := &tictactoe.Lookahead{Depth: *depth, N1: *n1, N2: *n2, Opponent: model}
+		for _, p := range []tictactoe.Player{player1, player2} {
+			switch lp := p.(type) {
+			case *tictactoe.MlannPlayer:
+				lp.SetLookahead(la)
+			case *tictactoe.GruPlayer:
+				lp.SetLookahead(la)
+			}
+		}
 	}
 
 	trainplayers(player1, player2, *episodes, 0.9)
-	player1.Persist(*net1path)
-	player2.Persist(*net2path)
+	if err := save(player1, *net1path); err != nil {
+		fmt.Println("error saving player 1,", err.Error())
+		os.Exit(1)
+	}
+	if err := save(player2, *net2path); err != nil {
+		fmt.Println("error saving player 2,", err.Error())
+		os.Exit(1)
+	}
+}
+
+// load reads a saved player from path. No path, or a path with nothing saved
+// at it yet, leaves the new player as it is.
+func load(p tictactoe.Player, path string) error {
+	if path == "" {
+		return nil
+	}
+	err := p.Load(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		fmt.Println("no saved player at", path, "starting a new one")
+		return nil
+	}
+	return err
+}
+
+// save writes p to path unless no path was given.
+func save(p tictactoe.Player, path string) error {
+	if path == "" {
+		return nil
+	}
+	return p.Persist(path)
 }
 
 func trainplayers(player1, player2 tictactoe.Player, episodes int, gamma float64) {
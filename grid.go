@@ -0,0 +1,148 @@
+package tictactoe
+
+import (
+	"bigfunbrewing.com/tensor"
+)
+
+// grid is a compact copy of a board used by the search based players. Cells
+// are indexed with loc so a grid lines up with the first nine rows of a
+// Position.
+type grid [9]int
+
+// lines holds the cell offsets of every row, column and diagonal.
+var lines = [8][3]int{
+	{loc(0, 0), loc(0, 1), loc(0, 2)},
+	{loc(1, 0), loc(1, 1), loc(1, 2)},
+	{loc(2, 0), loc(2, 1), loc(2, 2)},
+	{loc(0, 0), loc(1, 0), loc(2, 0)},
+	{loc(0, 1), loc(1, 1), loc(2, 1)},
+	{loc(0, 2), loc(1, 2), loc(2, 2)},
+	{loc(0, 0), loc(1, 1), loc(2, 2)},
+	{loc(0, 2), loc(1, 1), loc(2, 0)},
+}
+
+// gridOf copies the current state of b into a grid.
+func gridOf(b Board) (g grid) {
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			p, _ := b.Get(i, j)
+			g[loc(i, j)] = p
+		}
+	}
+	return
+}
+
+// cell converts a grid offset back into a board row and column.
+func cell(idx int) (row, col int) {
+	return idx % 3, idx / 3
+}
+
+// other returns the id of the opponent of pid.
+func other(pid int) int {
+	return 3 - pid
+}
+
+// winner follows the same convention as Board.GameOver. 0 means the game is
+// still in progress, 1 or 2 is the winning player and -1 is a tie.
+func (g *grid) winner() int {
+	for _, l := range lines {
+		if g[l[0]] != 0 && g[l[0]] == g[l[1]] && g[l[0]] == g[l[2]] {
+			return g[l[0]]
+		}
+	}
+	for i := range g {
+		if g[i] == 0 {
+			return 0
+		}
+	}
+	return -1
+}
+
+// empty returns the offsets of the unoccupied cells in board order, the same
+// order that ValidMoves produces moves in.
+func (g *grid) empty() []int {
+	out := make([]int, 0, 9)
+	for r := 0; r < 3; r++ {
+		for c := 0; c < 3; c++ {
+			if g[loc(r, c)] == 0 {
+				out = append(out, loc(r, c))
+			}
+		}
+	}
+	return out
+}
+
+// toMove returns the id of the player whose turn it is. Player 1 always moves
+// first so whenever both players have made the same number of moves it is
+// player 1's turn.
+func (g *grid) toMove() int {
+	ones, twos := 0, 0
+	for i := range g {
+		switch g[i] {
+		case 1:
+			ones++
+		case 2:
+			twos++
+		}
+	}
+	if ones > twos {
+		return 2
+	}
+	return 1
+}
+
+// move converts a grid offset into a Move for pid.
+func (g *grid) move(pid, idx int) *Move {
+	row, col := cell(idx)
+	return &Move{Pid: pid, Row: row, Col: col}
+}
+
+// board builds a BoardImp holding the state of g. The new board has an empty
+// GamePlayed since a grid does not record the moves that led to it.
+func (g *grid) board() *BoardImp {
+	b := &BoardImp{}
+	b.Reset()
+	for i := range g {
+		row, col := cell(i)
+		b.data[row][col] = g[i]
+	}
+	return b
+}
+
+// decode splits a Position recorded by BoardImp.Move into the board before the
+// move, the offset of the move and the id of the player who made it.
+func decode(p Position) (g grid, idx, pid int) {
+	t := (*tensor.Tensor[float64])(p)
+	idx = -1
+	for i := range g {
+		g[i] = int(t.Get(i, 0))
+		if v := int(t.Get(i+9, 0)); v != 0 {
+			idx, pid = i, v
+		}
+	}
+	return
+}
+
+// perspective encodes g as a 9 row column vector from the point of view of
+// pid. pid's marks are 1, the opponent's are -1 and empty cells are 0, so a
+// single network can play either side.
+func (g *grid) perspective(pid int) *tensor.Tensor[float64] {
+	data := make([]float64, 9)
+	for i := range g {
+		switch g[i] {
+		case pid:
+			data[i] = 1
+		case other(pid):
+			data[i] = -1
+		}
+	}
+	return tensor.New(tensor.WithShape[float64](9, 1), tensor.WithBacking[float64](data))
+}
+
+// transform returns g under symmetry k.
+func (g *grid) transform(k int) (out grid) {
+	for i := range g {
+		out[symmetries[k][i]] = g[i]
+	}
+	return
+}
@@ -0,0 +1,46 @@
+package tictactoe
+
+import (
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	b := NewBoard()
+	b.Reset()
+	moves := []*Move{{1, 1, 1}, {2, 0, 2}, {1, 2, 0}}
+	for _, mv := range moves {
+		if err := b.Move(mv); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	var want grid
+	for i, p := range b.GamePlayed().Positions() {
+		g, idx, pid := decode(p)
+		if g != want {
+			t.Errorf("position %d: expected board %v, got %v", i, want, g)
+		}
+		if idx != loc(moves[i].Row, moves[i].Col) || pid != moves[i].Pid {
+			t.Errorf("position %d: expected move %v, got %d by %d", i, *moves[i], idx, pid)
+		}
+		want[idx] = pid
+	}
+}
+
+func TestGridWinner(t *testing.T) {
+	type test struct {
+		b   *BoardImp
+		out int
+	}
+	tests := []test{
+		{b: &BoardImp{data: [][]int{{1, 1, 1}, {2, 2, 0}, {0, 0, 0}}}, out: 1},
+		{b: &BoardImp{data: [][]int{{1, 1, 2}, {0, 2, 0}, {2, 0, 1}}}, out: 2},
+		{b: &BoardImp{data: [][]int{{1, 2, 1}, {1, 2, 2}, {2, 1, 1}}}, out: -1},
+		{b: &BoardImp{data: [][]int{{1, 2, 0}, {0, 0, 0}, {0, 0, 0}}}, out: 0},
+	}
+	for i := range tests {
+		g := gridOf(tests[i].b)
+		if w := g.winner(); w != tests[i].out {
+			t.Errorf("test %d: expected %d, got %d", i, tests[i].out, w)
+		}
+	}
+}
@@ -1,9 +1,10 @@
 package tictactoe
 
 import (
+	"bytes"
 	"fmt"
+	"math"
 	"math/rand"
-	"os"
 
 	"bigfunbrewing.com/tensor"
 )
@@ -13,22 +14,32 @@ type GruPlayer struct {
 	output  *tensor.Network[float64]
 	pid     int
 	epsilon float64
+	// explore picks the moves the player tries instead of its best one
+	explore Explorer
+	// lookahead, when set, replaces the greedy choice of move
+	lookahead *Lookahead
+	// rollouts is the number of games simulated from each candidate move
+	// in rollout mode, 0 scores each candidate once
+	rollouts int
+	// episodes is the number of games the networks have trained on
+	episodes int
 }
 
-// NewPlayer from a previously persisted state. If the provided path is empty
-// then GruPlayer makes a new player with random initial state. Gru will be used
+// NewGruPlayer makes a new player with random initial state, call Load to
+// play a previously persisted one. Gru will be used
 // to estimate the reward for a given board position. So the input to Gru for a given
 // game would be a reward and a sequence of board positions from the first pair of moves
 // to the end of the game. Gru would play each game from the beginning, generating
 // new board states  from the positions already played. But, how would we convert the
 // continuous output of the gru as board positions? Not sure how this would work yet.
-func NewGruPlayer(pid int, path string, epsilon float64) *GruPlayer {
+func NewGruPlayer(pid int, epsilon float64) *GruPlayer {
 	outputs := 36
 	alpha := 0.01
 	lambda := 0.3
 	gp := &GruPlayer{
 		pid:     pid,
 		epsilon: epsilon,
+		explore: NewEpsilonGreedy(epsilon),
 		gru: tensor.NewGru(
 			18, //inputs per word 9 for just the board, 18 for board plus move
 			outputs,
@@ -45,19 +56,27 @@ func NewGruPlayer(pid int, path string, epsilon float64) *GruPlayer {
 		),
 	}
 
-	if path != "" {
-		if _, err := os.Stat(path); err == nil {
-			f, err := os.Open(path)
-			if err == nil {
-				gp.gru.Read(f)
-				gp.output.Read(f)
-			}
-		}
-	}
-
 	return gp
 }
 
+// Load reads the player's networks from the model file at path. Networks
+// trained as the other player are a ModelWrongSide error.
+func (gp *GruPlayer) Load(path string) error {
+	h, payload, _, err := openModel(path, "gruplayer")
+	if err != nil {
+		return err
+	}
+	// the board is encoded by player id, as for mlann players
+	if h.Pid != 0 && h.Pid != gp.pid {
+		return &ModelError{Path: path, Kind: ModelWrongSide, Detail: fmt.Sprintf("trained as player %d, not player %d", h.Pid, gp.pid)}
+	}
+	r := bytes.NewReader(payload)
+	gp.gru.Read(r)
+	gp.output.Read(r)
+	gp.episodes = h.Episodes
+	return nil
+}
+
 // This model kind of sucks cause it's picking a next move
 // and not a sequence of moves based on the current state.
 // But Gru was trained on complete games, where we treated
@@ -68,22 +87,27 @@ func NewGruPlayer(pid int, path string, epsilon float64) *GruPlayer {
 //
 // To get to this style of play, we pass the current Position (s_i, a_i)
 // through the network and pass the network output as the next
-// input to gru until
+// input to gru until the game ends. That is the rollout mode selected by
+// SetRollouts, see rollout.
 func (gp *GruPlayer) Move(b Board) (mv *Move, err error) {
 	moves, err := ValidMoves(b, gp.pid)
 	if err != nil {
 		fmt.Println("gru found no valid moves")
 		return nil, err
 	}
-	if rand.Float64() < gp.epsilon {
+	if i := gp.explore.Choose(b, moves, gp); i >= 0 {
 		//fmt.Printf(".")
-		mv, err = (&RandomPlayer{pid: gp.pid}).Move(b)
+		mv = moves[i]
+	} else if gp.lookahead != nil {
+		mv, err = gp.lookahead.Move(b, gp.pid, gp)
+	} else if gp.rollouts > 0 {
+		mv = gp.rolloutMove(b, moves)
 	} else {
-		yhat := gp.evalMove(b, moves[0])
+		yhat := gp.EvalMove(b, moves[0])
 		max := yhat
 		pos := 0
 		for i := 1; i < len(moves); i++ {
-			yhat = gp.evalMove(b, moves[i])
+			yhat = gp.EvalMove(b, moves[i])
 			if yhat > max {
 				max = yhat
 				pos = i
@@ -95,7 +119,123 @@ func (gp *GruPlayer) Move(b Board) (mv *Move, err error) {
 	return
 }
 
+// Epsilon returns the exploration rate of the player's explorer.
+func (gp *GruPlayer) Epsilon() float64 {
+	return gp.explore.Rate()
+}
+
+// SetEpsilon sets the exploration rate of the player's explorer.
+func (gp *GruPlayer) SetEpsilon(epsilon float64) {
+	gp.epsilon = epsilon
+	gp.explore.SetRate(epsilon)
+}
+
+// SetExplorer replaces the epsilon-greedy exploration the player starts
+// with.
+func (gp *GruPlayer) SetExplorer(e Explorer) {
+	gp.explore = e
+}
+
+// SetLookahead makes the player search ahead with la when it is not
+// exploring, nil restores the one ply greedy choice. A UCBExplorer picks
+// every move, so la has no effect with one.
+func (gp *GruPlayer) SetLookahead(la *Lookahead) {
+	gp.lookahead = la
+}
+
+// SetRollouts switches the player to rollout mode where each candidate move
+// is judged by n games the GRU plays forward from it. 0 goes back to scoring
+// each candidate once. Like a lookahead, rollouts are not used with a
+// UCBExplorer.
+func (gp *GruPlayer) SetRollouts(n int) {
+	gp.rollouts = n
+}
+
+// rolloutMove returns the candidate with the best mean outcome over the
+// simulated continuations, ties going to the candidate the GRU scores
+// higher.
+func (gp *GruPlayer) rolloutMove(b Board, moves []*Move) *Move {
+	best, bv, bs := 0, 0.0, 0.0
+	for i := range moves {
+		v := 0.0
+		for k := 0; k < gp.rollouts; k++ {
+			v += gp.rollout(b, moves[i])
+		}
+		v /= float64(gp.rollouts)
+		s := gp.EvalMove(b, moves[i])
+		if i == 0 || v > bv || (v == bv && s > bs) {
+			best, bv, bs = i, v, s
+		}
+	}
+	return moves[best]
+}
+
+// rollout plays mv on a copy of b and lets the GRU play out the rest of the
+// game for both sides, returning 1 if the player wins, -1 if it loses and 0
+// for a tie, discounted by 0.9 for every move after mv so that quick wins
+// beat slow ones. Each position played is appended to the game's sentence so the
+// GRU reads its own earlier choices when choosing the next move. Moves are
+// sampled from a softmax over the GRU's scores so that repeated rollouts
+// explore different continuations. The scores are the value of a move to
+// this player, so the opponent's replies are sampled from the negated scores
+// and favour the moves that hurt the player most.
+func (gp *GruPlayer) rollout(b Board, mv *Move) float64 {
+	rb := cloneBoard(b)
+	rb.Move(mv)
+	pid := gp.pid
+	discount := 1.0
+	for {
+		switch w := rb.GameOver(); w {
+		case 0:
+		case gp.pid:
+			return discount
+		case -1:
+			return 0
+		default:
+			return -discount
+		}
+		discount *= 0.9
+		pid = other(pid)
+		moves, err := ValidMoves(rb, pid)
+		if err != nil {
+			return 0
+		}
+		scores := make([]float64, len(moves))
+		for i := range moves {
+			scores[i] = gp.EvalMove(rb, moves[i])
+			if pid != gp.pid {
+				scores[i] = -scores[i]
+			}
+		}
+		rb.Move(moves[sampleSoftmax(scores, 1.0)])
+	}
+}
+
+// sampleSoftmax draws an index with probability proportional to
+// exp(values[i]/temperature).
+func sampleSoftmax(values []float64, temperature float64) int {
+	max := math.Inf(-1)
+	for _, v := range values {
+		max = math.Max(max, v)
+	}
+	weights := make([]float64, len(values))
+	sum := 0.0
+	for i, v := range values {
+		weights[i] = math.Exp((v - max) / temperature)
+		sum += weights[i]
+	}
+	r := rand.Float64() * sum
+	for i := range weights {
+		r -= weights[i]
+		if r <= 0 {
+			return i
+		}
+	}
+	return len(values) - 1
+}
+
 func (gp *GruPlayer) Train(games []*GamePlayed) {
+	gp.episodes += len(games)
 	//convert games played into sentences
 	ss := makeSequenceSamples(games, gp.pid, []float64{2.0, -2.0, 0.0})
 
@@ -133,19 +273,19 @@ func (gp *GruPlayer) Display(b Board) {
 		z, _ := b.Get(i, 0)
 		zero := convert(z)
 		if zero == "" {
-			v := gp.evalMove(b, &Move{Pid: gp.pid, Row: i, Col: 0})
+			v := gp.EvalMove(b, &Move{Pid: gp.pid, Row: i, Col: 0})
 			zero = fmt.Sprintf("%.5f", v)
 		}
 		o, _ := b.Get(i, 1)
 		one := convert(o)
 		if one == "" {
-			v := gp.evalMove(b, &Move{Pid: gp.pid, Row: i, Col: 1})
+			v := gp.EvalMove(b, &Move{Pid: gp.pid, Row: i, Col: 1})
 			one = fmt.Sprintf("%.5f", v)
 		}
 		t, _ := b.Get(i, 2)
 		two := convert(t)
 		if two == "" {
-			v := gp.evalMove(b, &Move{Pid: gp.pid, Row: i, Col: 2})
+			v := gp.EvalMove(b, &Move{Pid: gp.pid, Row: i, Col: 2})
 			two = fmt.Sprintf("%.5f", v)
 		}
 		fmt.Printf("    %d    | %s | %s | %s\n", i, zero, one, two)
@@ -157,7 +297,9 @@ func (gp *GruPlayer) Display(b Board) {
 	}
 }
 
-func (gp *GruPlayer) evalMove(b Board, mv *Move) float64 {
+// EvalMove returns the network's estimate of the value of mv on b given the
+// moves already played on b.
+func (gp *GruPlayer) EvalMove(b Board, mv *Move) float64 {
 	X := MakePosition(b, mv)
 	sentence := b.GamePlayed().Positions()
 	sentence = append(sentence, X)
@@ -170,13 +312,17 @@ func (gp *GruPlayer) evalMove(b Board, mv *Move) float64 {
 	return yhat.Get(0, 0)
 }
 
-func (gp *GruPlayer) Persist(path string) {
-	f, err := os.Create(path)
-	if err != nil {
-		fmt.Println(err.Error())
+func (gp *GruPlayer) Persist(path string) error {
+	var payload bytes.Buffer
+	gp.gru.Write(&payload)
+	gp.output.Write(&payload)
+	h := ModelHeader{
+		Type:     "gruplayer",
+		Pid:      gp.pid,
+		Hyper:    map[string]float64{"epsilon": gp.epsilon},
+		Episodes: gp.episodes,
 	}
-	gp.gru.Write(f)
-	gp.output.Write(f)
+	return saveModel(path, h, payload.Bytes())
 }
 
 // makeSequenceSamples converts a slice of GamePlayed into a slice of SequenceSample
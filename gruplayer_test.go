@@ -62,3 +62,30 @@ func TestGruMakeSequenceSample(t *testing.T) {
 		}
 	}
 }
+
+func TestGruRollout(t *testing.T) {
+	gp := NewGruPlayer(1, 0.0)
+	gp.SetRollouts(5)
+	b := NewBoard()
+	b.Reset()
+	for _, mv := range []*Move{{1, 0, 0}, {2, 1, 0}, {1, 0, 1}, {2, 1, 1}} {
+		if err := b.Move(mv); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	// every rollout from the winning move ends in a win straight away
+	if v := gp.rollout(b, &Move{Pid: 1, Row: 0, Col: 2}); v != 1 {
+		t.Errorf("expected the winning move to roll out as a win, got %.1f", v)
+	}
+	before := len(b.GamePlayed().Positions())
+	mv, err := gp.Move(b)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if mv.Row != 0 || mv.Col != 2 {
+		t.Errorf("expected the winning move (0,2), got (%d,%d)", mv.Row, mv.Col)
+	}
+	if len(b.GamePlayed().Positions()) != before {
+		t.Errorf("rollouts changed the game being played")
+	}
+}
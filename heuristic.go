@@ -0,0 +1,147 @@
+package tictactoe
+
+import (
+	"fmt"
+)
+
+// HeuristicPlayer follows the rule based strategy of Newell and Simon. It
+// takes the first of these that applies: win, block the opponent's win,
+// create a fork, block the opponent's fork, take the center, take the corner
+// opposite the opponent, take an empty corner, take an empty side. It is
+// deterministic and never loses, and unlike a network its reasons for a move
+// can be read off directly.
+type HeuristicPlayer struct {
+	pid int
+}
+
+func NewHeuristicPlayer(pid int) *HeuristicPlayer {
+	return &HeuristicPlayer{pid: pid}
+}
+
+var (
+	center  = loc(1, 1)
+	corners = []int{loc(0, 0), loc(0, 2), loc(2, 0), loc(2, 2)}
+	sides   = []int{loc(0, 1), loc(1, 0), loc(1, 2), loc(2, 1)}
+)
+
+// opposite returns the corner diagonally across from corner idx.
+func opposite(idx int) int {
+	row, col := cell(idx)
+	return loc(2-row, 2-col)
+}
+
+// winningCells returns the empty cells that complete a line for pid.
+func winningCells(g grid, pid int) []int {
+	out := make([]int, 0)
+	for _, idx := range g.empty() {
+		g[idx] = pid
+		if g.winner() == pid {
+			out = append(out, idx)
+		}
+		g[idx] = 0
+	}
+	return out
+}
+
+// forkCells returns the empty cells that leave pid with two ways to win.
+func forkCells(g grid, pid int) []int {
+	out := make([]int, 0)
+	for _, idx := range g.empty() {
+		g[idx] = pid
+		if len(winningCells(g, pid)) >= 2 {
+			out = append(out, idx)
+		}
+		g[idx] = 0
+	}
+	return out
+}
+
+// choose returns the cell to play on g and the name of the rule that picked
+// it.
+func (hp *HeuristicPlayer) choose(g grid) (int, string) {
+	opp := other(hp.pid)
+	if cells := winningCells(g, hp.pid); len(cells) > 0 {
+		return cells[0], "win"
+	}
+	if cells := winningCells(g, opp); len(cells) > 0 {
+		return cells[0], "block"
+	}
+	if cells := forkCells(g, hp.pid); len(cells) > 0 {
+		return cells[0], "fork"
+	}
+	if forks := forkCells(g, opp); len(forks) > 0 {
+		if len(forks) == 1 {
+			return forks[0], "block fork"
+		}
+		// the opponent has several forks so force them to block a two in a
+		// row instead, as long as the block does not hand them a fork
+		for _, idx := range g.empty() {
+			g[idx] = hp.pid
+			threats := winningCells(g, hp.pid)
+			if len(threats) == 1 {
+				g[threats[0]] = opp
+				safe := len(winningCells(g, opp)) < 2
+				g[threats[0]] = 0
+				if safe {
+					g[idx] = 0
+					return idx, "force block"
+				}
+			}
+			g[idx] = 0
+		}
+		return forks[0], "block fork"
+	}
+	if g[center] == 0 {
+		return center, "center"
+	}
+	for _, idx := range corners {
+		if g[idx] == opp && g[opposite(idx)] == 0 {
+			return opposite(idx), "opposite corner"
+		}
+	}
+	for _, idx := range corners {
+		if g[idx] == 0 {
+			return idx, "empty corner"
+		}
+	}
+	for _, idx := range sides {
+		if g[idx] == 0 {
+			return idx, "empty side"
+		}
+	}
+	return -1, ""
+}
+
+func (hp *HeuristicPlayer) Move(b Board) (mv *Move, err error) {
+	if _, err = ValidMoves(b, hp.pid); err != nil {
+		return nil, err
+	}
+	g := gridOf(b)
+	idx, _ := hp.choose(g)
+	return g.move(hp.pid, idx), nil
+}
+
+func (hp *HeuristicPlayer) Train(games []*GamePlayed) {
+	//do nothing
+}
+
+func (hp *HeuristicPlayer) Persist(path string) error {
+	//do nothing
+	return nil
+}
+
+func (hp *HeuristicPlayer) Load(path string) error {
+	//do nothing
+	return nil
+}
+
+// Display shows the move the player is about to make and the rule behind it.
+func (hp *HeuristicPlayer) Display(b Board) {
+	g := gridOf(b)
+	idx, rule := hp.choose(g)
+	if idx < 0 {
+		return
+	}
+	row, col := cell(idx)
+	fmt.Printf("heuristic player %d: %s at (%d,%d)\n\n", hp.pid, rule, row, col)
+}
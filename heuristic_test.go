@@ -0,0 +1,119 @@
+package tictactoe
+
+import (
+	"testing"
+)
+
+func TestHeuristicRules(t *testing.T) {
+	type test struct {
+		b    *BoardImp
+		pid  int
+		rule string
+		out  Move
+	}
+	tests := []test{
+		{
+			b: &BoardImp{data: [][]int{
+				{1, 1, 0},
+				{2, 2, 0},
+				{0, 0, 0},
+			}},
+			pid:  1,
+			rule: "win",
+			out:  Move{Pid: 1, Row: 0, Col: 2},
+		},
+		{
+			b: &BoardImp{data: [][]int{
+				{1, 1, 0},
+				{0, 2, 0},
+				{0, 0, 0},
+			}},
+			pid:  2,
+			rule: "block",
+			out:  Move{Pid: 2, Row: 0, Col: 2},
+		},
+		{
+			b: &BoardImp{data: [][]int{
+				{1, 0, 0},
+				{0, 2, 0},
+				{0, 0, 1},
+			}},
+			pid:  2,
+			rule: "force block",
+			out:  Move{Pid: 2, Row: 0, Col: 1},
+		},
+		{
+			b: &BoardImp{data: [][]int{
+				{1, 2, 0},
+				{0, 2, 0},
+				{0, 1, 0},
+			}},
+			pid:  1,
+			rule: "fork",
+			out:  Move{Pid: 1, Row: 2, Col: 0},
+		},
+		{
+			b: &BoardImp{data: [][]int{
+				{1, 0, 0},
+				{0, 0, 0},
+				{0, 0, 0},
+			}},
+			pid:  2,
+			rule: "center",
+			out:  Move{Pid: 2, Row: 1, Col: 1},
+		},
+		{
+			b: &BoardImp{data: [][]int{
+				{2, 0, 0},
+				{0, 1, 0},
+				{0, 0, 0},
+			}},
+			pid:  1,
+			rule: "opposite corner",
+			out:  Move{Pid: 1, Row: 2, Col: 2},
+		},
+		{
+			b: &BoardImp{data: [][]int{
+				{0, 0, 0},
+				{0, 0, 0},
+				{0, 0, 0},
+			}},
+			pid:  1,
+			rule: "center",
+			out:  Move{Pid: 1, Row: 1, Col: 1},
+		},
+	}
+	for i := range tests {
+		hp := NewHeuristicPlayer(tests[i].pid)
+		_, rule := hp.choose(gridOf(tests[i].b))
+		if rule != tests[i].rule {
+			t.Errorf("test %d: expected rule %q, got %q", i, tests[i].rule, rule)
+		}
+		mv, err := hp.Move(tests[i].b)
+		if err != nil {
+			t.Fatalf("test %d: %s", i, err.Error())
+		}
+		if *mv != tests[i].out {
+			t.Errorf("test %d: expected %v, got %v", i, tests[i].out, *mv)
+		}
+	}
+}
+
+func TestHeuristicNeverLoses(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		if w := playGame(t, NewHeuristicPlayer(1), NewRandomPlayer(2)); w == 2 {
+			t.Errorf("heuristic lost as player 1")
+		}
+		if w := playGame(t, NewRandomPlayer(1), NewHeuristicPlayer(2)); w == 1 {
+			t.Errorf("heuristic lost as player 2")
+		}
+	}
+	for i := 0; i < 20; i++ {
+		if w := playGame(t, NewHeuristicPlayer(1), NewMinimaxPlayer(2, TieRandom)); w != -1 {
+			t.Errorf("expected a tie against minimax as player 1, got %d", w)
+		}
+		if w := playGame(t, NewMinimaxPlayer(1, TieRandom), NewHeuristicPlayer(2)); w != -1 {
+			t.Errorf("expected a tie against minimax as player 2, got %d", w)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package tictactoe
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// MoveEvaluator is implemented by players that score a move on a board with
+// a value network, higher is better for the player making the move.
+type MoveEvaluator interface {
+	EvalMove(b Board, mv *Move) float64
+}
+
+// OpponentModel decides how a Lookahead expects the opponent to reply.
+type OpponentModel int
+
+const (
+	// OpponentMin assumes the opponent plays the reply that is worst for us.
+	OpponentMin OpponentModel = iota
+	// OpponentSelf assumes the opponent plays the reply the network rates
+	// highest when it scores the reply as the opponent's move.
+	OpponentSelf
+)
+
+// ParseOpponentModel converts the command line name of an opponent model
+// into an OpponentModel. One of {min, self}.
+func ParseOpponentModel(s string) (OpponentModel, error) {
+	switch s {
+	case "min", "":
+		return OpponentMin, nil
+	case "self":
+		return OpponentSelf, nil
+	}
+	return OpponentMin, fmt.Errorf("unknown opponent model %q", s)
+}
+
+// Lookahead is the beam search proposed on MlannPlayer.Move. It scores every
+// move on the board, then for the best N1 moves expands the best N2 replies
+// by the opponent and scores the position after the reply the same way,
+// Depth rounds deep. The move with the best value after the last round is
+// played. A Depth of 0 is the plain one ply greedy choice.
+//
+// Replies are ranked by scoring them with the network as the opponent's
+// move. With OpponentSelf only the top ranked reply is followed so N2 is
+// ignored. A move that wins is worth +Inf, a reply that wins is worth -Inf
+// and a move that fills the board keeps its own score.
+type Lookahead struct {
+	Depth    int
+	N1       int
+	N2       int
+	Opponent OpponentModel
+}
+
+// Move returns the move for pid on b chosen by the beam search using eval to
+// score moves.
+func (la *Lookahead) Move(b Board, pid int, eval MoveEvaluator) (*Move, error) {
+	if _, err := ValidMoves(b, pid); err != nil {
+		return nil, err
+	}
+	mv, _ := la.search(cloneBoard(b), pid, eval, la.Depth)
+	return mv, nil
+}
+
+// ranked returns the valid moves for pid on b and their scores, best first.
+func ranked(b Board, pid int, eval MoveEvaluator) ([]*Move, []float64) {
+	moves, err := ValidMoves(b, pid)
+	if err != nil {
+		return nil, nil
+	}
+	scores := make([]float64, len(moves))
+	for i := range moves {
+		scores[i] = eval.EvalMove(b, moves[i])
+	}
+	order := make([]int, len(moves))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return scores[order[i]] > scores[order[j]] })
+	outMoves := make([]*Move, len(moves))
+	outScores := make([]float64, len(moves))
+	for i, o := range order {
+		outMoves[i] = moves[o]
+		outScores[i] = scores[o]
+	}
+	return outMoves, outScores
+}
+
+// search returns the best move for pid on b and its value looking depth
+// rounds ahead.
+func (la *Lookahead) search(b *BoardImp, pid int, eval MoveEvaluator, depth int) (*Move, float64) {
+	moves, scores := ranked(b, pid, eval)
+	if depth <= 0 {
+		return moves[0], scores[0]
+	}
+	n1 := la.N1
+	if n1 <= 0 || n1 > len(moves) {
+		n1 = len(moves)
+	}
+	best, bv := 0, math.Inf(-1)
+	for i := 0; i < n1; i++ {
+		v := la.round(b, moves[i], scores[i], pid, eval, depth)
+		if i == 0 || v > bv {
+			best, bv = i, v
+		}
+	}
+	return moves[best], bv
+}
+
+// round plays mv on a copy of b and returns its value after the opponent's
+// reply.
+func (la *Lookahead) round(b *BoardImp, mv *Move, score float64, pid int, eval MoveEvaluator, depth int) float64 {
+	nb := cloneBoard(b)
+	nb.Move(mv)
+	g := gridOf(nb)
+	switch g.winner() {
+	case pid:
+		return math.Inf(1)
+	case -1:
+		return score
+	}
+	replies, _ := ranked(nb, other(pid), eval)
+	n2 := la.N2
+	if n2 <= 0 || n2 > len(replies) {
+		n2 = len(replies)
+	}
+	if la.Opponent == OpponentSelf {
+		n2 = 1
+	}
+	out := math.Inf(1)
+	for i := 0; i < n2; i++ {
+		rb := cloneBoard(nb)
+		rb.Move(replies[i])
+		rg := gridOf(rb)
+		var v float64
+		switch rg.winner() {
+		case other(pid):
+			v = math.Inf(-1)
+		case -1:
+			v = score
+		default:
+			_, v = la.search(rb, pid, eval, depth-1)
+		}
+		out = math.Min(out, v)
+	}
+	return out
+}
@@ -0,0 +1,49 @@
+package tictactoe
+
+import (
+	"testing"
+)
+
+// cellEvaluator scores a move by a fixed value for its cell.
+type cellEvaluator [9]float64
+
+func (ce cellEvaluator) EvalMove(b Board, mv *Move) float64 {
+	return ce[loc(mv.Row, mv.Col)]
+}
+
+func TestLookahead(t *testing.T) {
+	// player 2 threatens the middle row and the evaluator prefers the top
+	// right corner, which greedy play takes and loses.
+	b := &BoardImp{data: [][]int{
+		{1, 0, 0},
+		{2, 2, 0},
+		{0, 0, 1},
+	}}
+	var eval cellEvaluator
+	eval[loc(0, 2)] = 5
+
+	type test struct {
+		la  Lookahead
+		out Move
+	}
+	tests := []test{
+		{la: Lookahead{Depth: 0}, out: Move{Pid: 1, Row: 0, Col: 2}},
+		{la: Lookahead{Depth: 1, N1: 9, N2: 9, Opponent: OpponentMin}, out: Move{Pid: 1, Row: 1, Col: 2}},
+		{la: Lookahead{Depth: 2, N1: 3, N2: 2, Opponent: OpponentMin}, out: Move{Pid: 1, Row: 1, Col: 2}},
+		// every reply scores the same so the opponent is expected to play
+		// the first one, which does not take the win
+		{la: Lookahead{Depth: 1, N1: 9, Opponent: OpponentSelf}, out: Move{Pid: 1, Row: 0, Col: 2}},
+	}
+	for i := range tests {
+		mv, err := tests[i].la.Move(b, 1, eval)
+		if err != nil {
+			t.Fatalf("test %d: %s", i, err.Error())
+		}
+		if *mv != tests[i].out {
+			t.Errorf("test %d: expected %v, got %v", i, tests[i].out, *mv)
+		}
+	}
+	if b.data[0][2] != 0 || b.data[1][2] != 0 {
+		t.Errorf("lookahead changed the board")
+	}
+}
@@ -0,0 +1,239 @@
+package tictactoe
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// RolloutPolicy builds the Player that finishes the game for pid during an
+// MCTS playout.
+type RolloutPolicy func(pid int) Player
+
+// RandomRollout finishes playouts with uniformly random moves.
+func RandomRollout(pid int) Player {
+	return NewRandomPlayer(pid)
+}
+
+// MCTSPlayer chooses moves with Monte Carlo Tree Search using UCT to select
+// nodes. Its strength grows with the number of playouts, so it can be tuned
+// anywhere between random and perfect play.
+type MCTSPlayer struct {
+	pid int
+	// playouts caps the number of playouts per move, 0 for no cap
+	playouts int
+	// budget caps the time spent searching per move, 0 for no cap
+	budget time.Duration
+	// c is the UCT exploration constant
+	c       float64
+	rollout [2]Player
+	policy  RolloutPolicy
+}
+
+// NewMCTSPlayer returns a player that runs up to playouts simulations or
+// searches for budget, whichever runs out first, before every move. When both
+// are 0 it runs 1000 playouts. c is the UCT exploration constant, sqrt(2) is
+// the usual choice. rollout picks the players that finish each playout, nil
+// means random play.
+func NewMCTSPlayer(pid, playouts int, budget time.Duration, c float64, rollout RolloutPolicy) *MCTSPlayer {
+	if playouts <= 0 && budget <= 0 {
+		playouts = 1000
+	}
+	if rollout == nil {
+		rollout = RandomRollout
+	}
+	return &MCTSPlayer{
+		pid:      pid,
+		playouts: playouts,
+		budget:   budget,
+		c:        c,
+		rollout:  [2]Player{rollout(1), rollout(2)},
+		policy:   rollout,
+	}
+}
+
+// node is a position in the search tree. value accumulates the rewards seen
+// by the player who made the move leading to the node, so a parent can pick
+// the child that is best for the player to move at the parent. prior is only
+// used by searches guided by a policy.
+type node struct {
+	g        grid
+	toMove   int
+	idx      int
+	parent   *node
+	children []*node
+	untried  []int
+	visits   float64
+	value    float64
+	prior    float64
+}
+
+func newNode(g grid, idx int, parent *node) *node {
+	n := &node{g: g, toMove: g.toMove(), idx: idx, parent: parent}
+	if g.winner() == 0 {
+		n.untried = g.empty()
+	}
+	return n
+}
+
+// Move searches from the current board and plays the most visited move.
+func (mp *MCTSPlayer) Move(b Board) (mv *Move, err error) {
+	moves, err := ValidMoves(b, mp.pid)
+	if err != nil {
+		return nil, err
+	}
+	if len(moves) == 1 {
+		return moves[0], nil
+	}
+	root := mp.search(gridOf(b))
+	best := root.children[0]
+	for _, child := range root.children[1:] {
+		if child.visits > best.visits {
+			best = child
+		}
+	}
+	return root.g.move(mp.pid, best.idx), nil
+}
+
+// search grows a tree from g until the playout or time budget is spent. It
+// always runs at least one playout so the root has a move to play, however
+// short the budget.
+func (mp *MCTSPlayer) search(g grid) *node {
+	root := newNode(g, -1, nil)
+	start := time.Now()
+	for i := 0; ; i++ {
+		if mp.playouts > 0 && i >= mp.playouts {
+			break
+		}
+		if i > 0 && mp.budget > 0 && time.Since(start) >= mp.budget {
+			break
+		}
+		n := mp.selectNode(root)
+		n = mp.expand(n)
+		backup(n, reward(n, mp.simulate(n)))
+	}
+	return root
+}
+
+// selectNode walks down the tree following the UCT rule until it finds a
+// node that still has untried moves or ends the game.
+func (mp *MCTSPlayer) selectNode(n *node) *node {
+	for len(n.untried) == 0 && len(n.children) > 0 {
+		best := n.children[0]
+		bv := mp.uct(n, best)
+		for _, child := range n.children[1:] {
+			if v := mp.uct(n, child); v > bv {
+				best, bv = child, v
+			}
+		}
+		n = best
+	}
+	return n
+}
+
+func (mp *MCTSPlayer) uct(parent, child *node) float64 {
+	return child.value/child.visits + mp.c*math.Sqrt(math.Log(parent.visits)/child.visits)
+}
+
+// expand adds a child for the next untried move of n.
+func (mp *MCTSPlayer) expand(n *node) *node {
+	if len(n.untried) == 0 {
+		return n
+	}
+	idx := n.untried[0]
+	n.untried = n.untried[1:]
+	g := n.g
+	g[idx] = n.toMove
+	child := newNode(g, idx, n)
+	n.children = append(n.children, child)
+	return child
+}
+
+// simulate plays the game out from n with the rollout players and returns
+// the result using the same convention as Board.GameOver.
+func (mp *MCTSPlayer) simulate(n *node) int {
+	if w := n.g.winner(); w != 0 {
+		return w
+	}
+	b := n.g.board()
+	pid := n.toMove
+	for {
+		mv, err := mp.rollout[pid-1].Move(b)
+		if err == nil {
+			err = b.Move(mv)
+		}
+		if err != nil {
+			// a policy that cannot finish the game hands over to random play
+			mv, _ = NewRandomPlayer(pid).Move(b)
+			b.Move(mv)
+		}
+		if w := b.GameOver(); w != 0 {
+			return w
+		}
+		pid = other(pid)
+	}
+}
+
+// reward converts the result w of a game into the reward for the player who
+// moved into n. A win is worth 1, a tie 0.5 and a loss 0.
+func reward(n *node, w int) float64 {
+	switch w {
+	case -1:
+		return 0.5
+	case other(n.toMove):
+		return 1
+	}
+	return 0
+}
+
+// backup credits r to n and every node above it up to the root. The players
+// alternate on the way up so the reward flips at each level.
+func backup(n *node, r float64) {
+	for ; n != nil; n = n.parent {
+		n.visits++
+		n.value += r
+		r = 1 - r
+	}
+}
+
+func (mp *MCTSPlayer) Train(games []*GamePlayed) {
+	//do nothing
+}
+
+func (mp *MCTSPlayer) Persist(path string) error {
+	//do nothing
+	return nil
+}
+
+func (mp *MCTSPlayer) Load(path string) error {
+	//do nothing
+	return nil
+}
+
+// Display runs a search from the current board and shows the expected score
+// of each move, 1 for a certain win, 0.5 for a tie and 0 for a certain loss.
+func (mp *MCTSPlayer) Display(b Board) {
+	root := mp.search(gridOf(b))
+	scores := make(map[int]string)
+	for _, child := range root.children {
+		scores[child.idx] = fmt.Sprintf("%9.5f", child.value/child.visits)
+	}
+	fmt.Println("         |     0     |     1     |     2     ")
+	fmt.Println("---------+-----------+-----------+-----------")
+	for i := 0; i < 3; i++ {
+		cells := make([]string, 3)
+		for j := 0; j < 3; j++ {
+			p, _ := b.Get(i, j)
+			cells[j] = convert(p)
+			if cells[j] == "" {
+				cells[j] = scores[loc(i, j)]
+			}
+		}
+		fmt.Printf("    %d    | %s | %s | %s\n", i, cells[0], cells[1], cells[2])
+		if i < 2 {
+			fmt.Println("---------+---------+---------+---------")
+		} else {
+			fmt.Println()
+		}
+	}
+}
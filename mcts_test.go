@@ -0,0 +1,82 @@
+package tictactoe
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMCTSMove(t *testing.T) {
+	type test struct {
+		b   *BoardImp
+		pid int
+		out Move
+	}
+	tests := []test{
+		{
+			b: &BoardImp{data: [][]int{
+				{1, 1, 0},
+				{2, 2, 0},
+				{0, 0, 0},
+			}},
+			pid: 1,
+			out: Move{Pid: 1, Row: 0, Col: 2},
+		},
+		{
+			b: &BoardImp{data: [][]int{
+				{1, 1, 0},
+				{0, 2, 0},
+				{0, 0, 0},
+			}},
+			pid: 2,
+			out: Move{Pid: 2, Row: 0, Col: 2},
+		},
+	}
+	for i := range tests {
+		mv, err := NewMCTSPlayer(tests[i].pid, 2000, 0, 1.4, nil).Move(tests[i].b)
+		if err != nil {
+			t.Fatalf("test %d: %s", i, err.Error())
+		}
+		if *mv != tests[i].out {
+			t.Errorf("test %d: expected %v, got %v", i, tests[i].out, *mv)
+		}
+	}
+}
+
+func TestMCTSBudget(t *testing.T) {
+	mp := NewMCTSPlayer(1, 0, 20*time.Millisecond, 1.4, RandomRollout)
+	b := NewBoard()
+	b.Reset()
+	start := time.Now()
+	if _, err := mp.Move(b); err != nil {
+		t.Fatal(err.Error())
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("search ignored its time budget, took %s", d)
+	}
+}
+
+func TestMCTSExpiredBudget(t *testing.T) {
+	// the budget runs out before the first playout can start
+	mp := NewMCTSPlayer(1, 0, time.Nanosecond, 1.4, RandomRollout)
+	b := NewBoard()
+	b.Reset()
+	mv, err := mp.Move(b)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if v, _ := b.Get(mv.Row, mv.Col); v != 0 || mv.Pid != 1 {
+		t.Errorf("invalid move %v", *mv)
+	}
+}
+
+func TestMCTSBeatsRandom(t *testing.T) {
+	losses := 0
+	for i := 0; i < 20; i++ {
+		if playGame(t, NewMCTSPlayer(1, 1000, 0, 1.4, nil), NewRandomPlayer(2)) == 2 {
+			losses++
+		}
+	}
+	if losses > 2 {
+		t.Errorf("mcts lost %d of 20 games against a random player", losses)
+	}
+}
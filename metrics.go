@@ -0,0 +1,174 @@
+package tictactoe
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Metrics records a batch of training games. The rates are shares of the
+// games in the batch. Values that were not measured for the batch, such as
+// the loss of a player without a network or an evaluation that was not due,
+// are nil.
+type Metrics struct {
+	Episode int       `json:"episode"`
+	Batch   int       `json:"batch"`
+	Time    time.Time `json:"time"`
+	One     float64   `json:"one"`
+	Two     float64   `json:"two"`
+	Draw    float64   `json:"draw"`
+	GameLen float64   `json:"gamelen"`
+	// Loss is the training loss of each player, Epsilon its exploration
+	// rate and Eval the share of optimal moves from Evaluate.
+	Loss1    *float64 `json:"loss1,omitempty"`
+	Loss2    *float64 `json:"loss2,omitempty"`
+	Epsilon1 *float64 `json:"epsilon1,omitempty"`
+	Epsilon2 *float64 `json:"epsilon2,omitempty"`
+	Eval1    *float64 `json:"eval1,omitempty"`
+	Eval2    *float64 `json:"eval2,omitempty"`
+}
+
+// metricsColumns is the CSV header, in the order of the Metrics fields.
+var metricsColumns = []string{"episode", "batch", "time", "one", "two", "draw", "gamelen", "loss1", "loss2", "epsilon1", "epsilon2", "eval1", "eval2"}
+
+// optional returns the fields of m that may be missing in column order.
+func (m *Metrics) optional() []**float64 {
+	return []**float64{&m.Loss1, &m.Loss2, &m.Epsilon1, &m.Epsilon2, &m.Eval1, &m.Eval2}
+}
+
+// MetricsFormat returns the format of a metrics file from its extension,
+// csv for .csv and jsonl for anything else.
+func MetricsFormat(path string) string {
+	if filepath.Ext(path) == ".csv" {
+		return "csv"
+	}
+	return "jsonl"
+}
+
+// MetricsWriter writes Metrics as CSV or as JSON lines, one record per line.
+// Every record is flushed as it is written so an interrupted run keeps all
+// but the batch it was playing.
+type MetricsWriter struct {
+	csv  *csv.Writer
+	json *json.Encoder
+}
+
+// NewMetricsWriter returns a writer in format, csv or jsonl. header writes
+// the CSV header first, leave it off when appending to an existing file.
+func NewMetricsWriter(w io.Writer, format string, header bool) (*MetricsWriter, error) {
+	switch format {
+	case "csv":
+		mw := &MetricsWriter{csv: csv.NewWriter(w)}
+		if header {
+			mw.csv.Write(metricsColumns)
+			mw.csv.Flush()
+			if err := mw.csv.Error(); err != nil {
+				return nil, err
+			}
+		}
+		return mw, nil
+	case "jsonl":
+		return &MetricsWriter{json: json.NewEncoder(w)}, nil
+	}
+	return nil, fmt.Errorf("unknown metrics format %q", format)
+}
+
+// Write writes m as one record.
+func (mw *MetricsWriter) Write(m Metrics) error {
+	if mw.json != nil {
+		return mw.json.Encode(m)
+	}
+	row := []string{
+		strconv.Itoa(m.Episode),
+		strconv.Itoa(m.Batch),
+		m.Time.Format(time.RFC3339),
+		formatFloat(m.One),
+		formatFloat(m.Two),
+		formatFloat(m.Draw),
+		formatFloat(m.GameLen),
+	}
+	for _, v := range m.optional() {
+		if *v == nil {
+			row = append(row, "")
+		} else {
+			row = append(row, formatFloat(**v))
+		}
+	}
+	mw.csv.Write(row)
+	mw.csv.Flush()
+	return mw.csv.Error()
+}
+
+// ReadMetrics reads the records written by a MetricsWriter in format.
+func ReadMetrics(r io.Reader, format string) ([]Metrics, error) {
+	out := make([]Metrics, 0)
+	switch format {
+	case "jsonl":
+		s := bufio.NewScanner(r)
+		for n := 1; s.Scan(); n++ {
+			if len(s.Bytes()) == 0 {
+				continue
+			}
+			var m Metrics
+			if err := json.Unmarshal(s.Bytes(), &m); err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+			out = append(out, m)
+		}
+		return out, s.Err()
+	case "csv":
+		rows, err := csv.NewReader(r).ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		for n, row := range rows {
+			if n == 0 && len(row) > 0 && row[0] == metricsColumns[0] {
+				continue
+			}
+			m, err := parseMetrics(row)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+1, err)
+			}
+			out = append(out, m)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("unknown metrics format %q", format)
+}
+
+// parseMetrics converts a CSV row back into Metrics.
+func parseMetrics(row []string) (m Metrics, err error) {
+	if len(row) != len(metricsColumns) {
+		return m, fmt.Errorf("expected %d columns, got %d", len(metricsColumns), len(row))
+	}
+	if m.Episode, err = strconv.Atoi(row[0]); err != nil {
+		return m, err
+	}
+	if m.Batch, err = strconv.Atoi(row[1]); err != nil {
+		return m, err
+	}
+	if m.Time, err = time.Parse(time.RFC3339, row[2]); err != nil {
+		return m, err
+	}
+	for i, f := range []*float64{&m.One, &m.Two, &m.Draw, &m.GameLen} {
+		if *f, err = strconv.ParseFloat(row[3+i], 64); err != nil {
+			return m, err
+		}
+	}
+	for i, v := range m.optional() {
+		if row[7+i] == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(row[7+i], 64)
+		if err != nil {
+			return m, err
+		}
+		*v = &f
+	}
+	return m, nil
+}
@@ -0,0 +1,65 @@
+package tictactoe
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMetricsRoundTrip(t *testing.T) {
+	loss, eps, eval := 0.25, 0.1, 0.875
+	saved := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	in := []Metrics{
+		{Episode: 20, Batch: 1, Time: saved, One: 0.5, Two: 0.25, Draw: 0.25, GameLen: 7.5, Loss1: &loss, Epsilon1: &eps},
+		{Episode: 40, Batch: 2, Time: saved, One: 0.4, Two: 0.4, Draw: 0.2, GameLen: 6, Eval2: &eval},
+	}
+	for _, format := range []string{"csv", "jsonl"} {
+		var buf bytes.Buffer
+		mw, err := NewMetricsWriter(&buf, format, true)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		for _, m := range in {
+			if err := mw.Write(m); err != nil {
+				t.Fatal(err.Error())
+			}
+		}
+		out, err := ReadMetrics(&buf, format)
+		if err != nil {
+			t.Fatalf("%s: %s", format, err.Error())
+		}
+		if len(out) != len(in) {
+			t.Fatalf("%s: expected %d records, got %d", format, len(in), len(out))
+		}
+		for i := range in {
+			a, b := in[i], out[i]
+			if a.Episode != b.Episode || a.Batch != b.Batch || !a.Time.Equal(b.Time) || a.One != b.One || a.GameLen != b.GameLen {
+				t.Errorf("%s record %d: expected %+v, got %+v", format, i, a, b)
+			}
+			for j, v := range a.optional() {
+				w := b.optional()[j]
+				if (*v == nil) != (*w == nil) || (*v != nil && math.Abs(**v-**w) > 1e-9) {
+					t.Errorf("%s record %d: field %s does not match", format, i, metricsColumns[7+j])
+				}
+			}
+		}
+	}
+	if _, err := NewMetricsWriter(&bytes.Buffer{}, "xml", true); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+	if MetricsFormat("run.csv") != "csv" || MetricsFormat("run.jsonl") != "jsonl" {
+		t.Errorf("unexpected formats from file extensions")
+	}
+}
+
+func TestMlannLoss(t *testing.T) {
+	mp := NewMlannPlayer(1, 0.1, 0.9)
+	if !math.IsNaN(mp.Loss()) {
+		t.Errorf("expected no loss before training, got %f", mp.Loss())
+	}
+	mp.Train([]*GamePlayed{recordGame(t, NewMinimaxPlayer(1, TieFirst), NewRandomPlayer(2))})
+	if l := mp.Loss(); math.IsNaN(l) || l < 0 {
+		t.Errorf("expected a loss after training, got %f", l)
+	}
+}
@@ -0,0 +1,134 @@
+package tictactoe
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// TieBreak decides which move MinimaxPlayer plays when several moves share
+// the best game theoretic value.
+type TieBreak int
+
+const (
+	// TieFirst plays the first of the best moves in ValidMoves order.
+	TieFirst TieBreak = iota
+	// TieRandom plays a uniformly random choice among the best moves.
+	TieRandom
+	// TieFastest prefers the quickest win, or the slowest loss.
+	TieFastest
+)
+
+// ParseTieBreak converts the command line name of a tie breaking rule into a
+// TieBreak. One of {first, random, fastest}.
+func ParseTieBreak(s string) (TieBreak, error) {
+	switch s {
+	case "first", "":
+		return TieFirst, nil
+	case "random":
+		return TieRandom, nil
+	case "fastest":
+		return TieFastest, nil
+	}
+	return TieFirst, fmt.Errorf("unknown tie break %q", s)
+}
+
+// MinimaxPlayer plays perfectly by searching the full game tree from the
+// current board. It never learns anything and is meant to be the ground truth
+// opponent for the learning players.
+type MinimaxPlayer struct {
+	pid    int
+	tie    TieBreak
+	search *Searcher
+}
+
+func NewMinimaxPlayer(pid int, tie TieBreak) *MinimaxPlayer {
+	return &MinimaxPlayer{pid: pid, tie: tie, search: NewSearcher()}
+}
+
+// Move scores every valid move with a full game tree search and plays the best
+// one, using the tie break rule to choose among equally good moves.
+func (mp *MinimaxPlayer) Move(b Board) (mv *Move, err error) {
+	moves, err := ValidMoves(b, mp.pid)
+	if err != nil {
+		return nil, err
+	}
+	g := gridOf(b)
+	best := make([]*Move, 0, len(moves))
+	var bv int
+	for i := range moves {
+		v := mp.score(g, moves[i])
+		if len(best) == 0 || v > bv {
+			bv = v
+			best = best[:0]
+		}
+		if v == bv {
+			best = append(best, moves[i])
+		}
+	}
+	mv = best[0]
+	if mp.tie == TieRandom {
+		mv = best[rand.Intn(len(best))]
+	}
+	return
+}
+
+// score is the value of mv for the player. Unless the player prefers fast
+// wins only the outcome matters, so the size of the value is dropped.
+func (mp *MinimaxPlayer) score(g grid, mv *Move) int {
+	mp.search.mu.Lock()
+	v := mp.search.moveValue(g, mv)
+	mp.search.mu.Unlock()
+	if mp.tie == TieFastest {
+		return v
+	}
+	return sign(v)
+}
+
+func sign(v int) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
+
+func (mp *MinimaxPlayer) Train(games []*GamePlayed) {
+	//do nothing
+}
+
+func (mp *MinimaxPlayer) Persist(path string) error {
+	//do nothing
+	return nil
+}
+
+func (mp *MinimaxPlayer) Load(path string) error {
+	//do nothing
+	return nil
+}
+
+// Display shows the outcome the player expects from each empty cell, 1 for a
+// forced win, -1 for a forced loss and 0 for a tie under perfect play.
+func (mp *MinimaxPlayer) Display(b Board) {
+	g := gridOf(b)
+	fmt.Println("         |     0     |     1     |     2     ")
+	fmt.Println("---------+-----------+-----------+-----------")
+	for i := 0; i < 3; i++ {
+		cells := make([]string, 3)
+		for j := 0; j < 3; j++ {
+			p, _ := b.Get(i, j)
+			cells[j] = convert(p)
+			if cells[j] == "" {
+				v := sign(mp.score(g, &Move{Pid: mp.pid, Row: i, Col: j}))
+				cells[j] = fmt.Sprintf("%9d", v)
+			}
+		}
+		fmt.Printf("    %d    | %s | %s | %s\n", i, cells[0], cells[1], cells[2])
+		if i < 2 {
+			fmt.Println("---------+---------+---------+---------")
+		} else {
+			fmt.Println()
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package tictactoe
+
+import (
+	"testing"
+)
+
+// playGame plays a single game between player1 and player2 and returns the
+// value of GameOver for the final board.
+func playGame(t *testing.T, player1, player2 Player) int {
+	_, w, err := PlayGame(player1, player2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	return w
+}
+
+// recordGame plays a single game between player1 and player2 and returns the
+// game played.
+func recordGame(t *testing.T, player1, player2 Player) *GamePlayed {
+	g, _, err := PlayGame(player1, player2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	return g
+}
+
+func TestMinimaxMove(t *testing.T) {
+	type test struct {
+		b   *BoardImp
+		pid int
+		tie TieBreak
+		out Move
+	}
+	tests := []test{
+		{
+			// player 1 takes the win on the top row
+			b: &BoardImp{data: [][]int{
+				{1, 1, 0},
+				{2, 2, 0},
+				{0, 0, 0},
+			}},
+			pid: 1,
+			tie: TieFirst,
+			out: Move{Pid: 1, Row: 0, Col: 2},
+		},
+		{
+			// player 2 must block the top row
+			b: &BoardImp{data: [][]int{
+				{1, 1, 0},
+				{0, 2, 0},
+				{0, 0, 0},
+			}},
+			pid: 2,
+			tie: TieFirst,
+			out: Move{Pid: 2, Row: 0, Col: 2},
+		},
+		{
+			// (2,0) and (2,2) both win for player 1 eventually but only
+			// (2,2) wins immediately.
+			b: &BoardImp{data: [][]int{
+				{1, 2, 2},
+				{0, 1, 0},
+				{0, 0, 0},
+			}},
+			pid: 1,
+			tie: TieFastest,
+			out: Move{Pid: 1, Row: 2, Col: 2},
+		},
+	}
+	for i := range tests {
+		mv, err := NewMinimaxPlayer(tests[i].pid, tests[i].tie).Move(tests[i].b)
+		if err != nil {
+			t.Fatalf("test %d: %s", i, err.Error())
+		}
+		if *mv != tests[i].out {
+			t.Errorf("test %d: expected %v, got %v", i, tests[i].out, *mv)
+		}
+	}
+}
+
+func TestMinimaxNeverLoses(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		if w := playGame(t, NewMinimaxPlayer(1, TieRandom), NewRandomPlayer(2)); w == 2 {
+			t.Errorf("minimax lost as player 1")
+		}
+		if w := playGame(t, NewRandomPlayer(1), NewMinimaxPlayer(2, TieRandom)); w == 1 {
+			t.Errorf("minimax lost as player 2")
+		}
+	}
+	if w := playGame(t, NewMinimaxPlayer(1, TieFastest), NewMinimaxPlayer(2, TieFastest)); w != -1 {
+		t.Errorf("expected perfect play to tie, got %d", w)
+	}
+}
@@ -1,13 +1,55 @@
 package tictactoe
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
-	"math/rand"
+	"math"
 	"os"
 
 	"bigfunbrewing.com/tensor"
 )
 
+// TargetMode selects how MlannPlayer.Train builds the regression target for
+// each move the player made.
+type TargetMode int
+
+const (
+	// MonteCarlo discounts the final reward of the game back to every move.
+	MonteCarlo TargetMode = iota
+	// TD0 is the one-step target gamma*V(s_{t+1}), the value of the next
+	// state being the expected score of its moves under the player's
+	// epsilon-greedy policy, E[Q(s_{t+1}, a)].
+	TD0
+	// SARSA bootstraps from the move the player actually made next,
+	// gamma*Q(s_{t+1}, a_{t+1}).
+	SARSA
+	// QLearning bootstraps from the best move available in the next state,
+	// gamma*max_a Q(s_{t+1}, a).
+	QLearning
+	// TDLambda blends the SARSA estimates of every later move with the
+	// final reward using eligibility traces that decay by lambda.
+	TDLambda
+)
+
+// ParseTargetMode converts the command line name of a target mode into a
+// TargetMode. One of {montecarlo, td0, sarsa, qlearning, tdlambda}.
+func ParseTargetMode(s string) (TargetMode, error) {
+	switch s {
+	case "montecarlo", "":
+		return MonteCarlo, nil
+	case "td0":
+		return TD0, nil
+	case "sarsa":
+		return SARSA, nil
+	case "qlearning":
+		return QLearning, nil
+	case "tdlambda":
+		return TDLambda, nil
+	}
+	return MonteCarlo, fmt.Errorf("unknown target mode %q", s)
+}
+
 // MlannPlayer Uses an existing network to determine moves.
 type MlannPlayer struct {
 	// pid is the id of the player either 1 or 2
@@ -15,37 +57,134 @@ type MlannPlayer struct {
 	epsilon float64
 	gamma   float64
 	net     *tensor.Network[float64]
+	// explore picks the moves the player tries instead of its best one
+	explore Explorer
+	// cfg describes the network and is saved with it
+	cfg NetConfig
+	// episodes is the number of games the network has trained on
+	episodes int
+	// target is how training targets are computed, lambda is the trace
+	// decay used by TDLambda
+	target TargetMode
+	lambda float64
+	// replay, when set, is the experience replay buffer the player trains
+	// from and frozen is the target network for the replay updates
+	replay    *ReplayBuffer
+	batch     int
+	syncEvery int
+	trains    int
+	frozen    *tensor.Network[float64]
+	// lookahead, when set, replaces the greedy choice of move
+	lookahead *Lookahead
+	// loss is the error of the network on the last batch it trained on,
+	// NaN when the last call to Train did not train it
+	loss float64
 }
 
-func NewMlannPlayer(pid int, path string, epsilon, gamma float64) *MlannPlayer {
-	var net *tensor.Network[float64]
-	alpha := 0.05
-	lambda := 0.3
-	net = tensor.NewNetwork(
-		tensor.SquaredError[float64],
-		tensor.SquaredErrorPrime[float64],
-		50,
-		tensor.NewDense[float64](18, 36, 1.0, 0.1, tensor.LeakyRelu[float64]{Leak: 0.1}, "adam", alpha, lambda),
-		tensor.NewDense[float64](36, 36, 1.0, 0.1, tensor.LeakyRelu[float64]{Leak: 0.1}, "adam", alpha, lambda),
-		tensor.NewDense[float64](36, 36, 1.0, 0.1, tensor.LeakyRelu[float64]{Leak: 0.1}, "adam", alpha, lambda),
-		//tensor.NewDense[float64](36, 36, 1.0, 0.1, tensor.LeakyRelu[float64]{Leak: 0.1}, "adam", alpha, lambda),
-		tensor.NewDense[float64](36, 18, 1.0, 0.1, tensor.LeakyRelu[float64]{Leak: 0.1}, "adam", alpha, lambda),
-		tensor.NewDense[float64](18, 1, 1.0, 0.1, tensor.LeakyRelu[float64]{Leak: 0.1}, "adam", alpha, lambda),
-	)
-
-	if path != "" {
-		f, err := os.Open(path)
-		if err == nil {
-			net.Read(f)
-		} else {
-			fmt.Println(err.Error())
+// NewMlannPlayer returns a player with a new network, call Load to play a
+// saved one.
+func NewMlannPlayer(pid int, epsilon, gamma float64) *MlannPlayer {
+	return NewMlannPlayerConfig(pid, epsilon, gamma, DefaultNetConfig())
+}
+
+// NewMlannPlayerConfig is NewMlannPlayer with the network described by cfg.
+// A network read by Load keeps the config it was saved with, cfg only
+// applies to new networks.
+func NewMlannPlayerConfig(pid int, epsilon, gamma float64, cfg NetConfig) *MlannPlayer {
+	if err := cfg.Validate(); err != nil {
+		fmt.Println(err.Error(), "using the default network")
+		cfg = DefaultNetConfig()
+	}
+	mp := &MlannPlayer{pid: pid, epsilon: epsilon, gamma: gamma, explore: NewEpsilonGreedy(epsilon), cfg: cfg, loss: math.NaN()}
+	mp.net = newMlannNetwork(cfg)
+	return mp
+}
+
+// Load replaces the player's network with the one saved at path, which keeps
+// the config it was saved with. A network trained as the other player is a
+// ModelWrongSide error, the board is encoded by player id so it would misread
+// every position. On error the player is left unchanged.
+func (mp *MlannPlayer) Load(path string) error {
+	h, payload, legacy, err := openModel(path, "mlannplayer")
+	if err != nil {
+		return err
+	}
+	r := bufio.NewReader(bytes.NewReader(payload))
+	cfg := DefaultNetConfig()
+	if legacy {
+		if cfg, _, err = readNetConfig(r); err != nil {
+			return &ModelError{Path: path, Kind: ModelCorrupt, Detail: err.Error()}
 		}
+	} else if h.Arch != nil {
+		cfg = *h.Arch
 	}
-	return &MlannPlayer{pid: pid, epsilon: epsilon, gamma: gamma, net: net}
+	if h.Pid != 0 && h.Pid != mp.pid {
+		return &ModelError{Path: path, Kind: ModelWrongSide, Detail: fmt.Sprintf("trained as player %d, not player %d", h.Pid, mp.pid)}
+	}
+	net := newMlannNetwork(cfg)
+	net.Read(r)
+	mp.net = net
+	mp.cfg = cfg
+	mp.episodes = h.Episodes
+	return nil
+}
+
+// Config returns the config of the player's network.
+func (mp *MlannPlayer) Config() NetConfig {
+	return mp.cfg
+}
+
+// Epsilon returns the exploration rate of the player's explorer.
+func (mp *MlannPlayer) Epsilon() float64 {
+	return mp.explore.Rate()
+}
+
+// Loss returns the mean squared error of the network on the last batch it
+// trained on, measured after the update, or NaN if the last call to Train
+// did not train the network.
+func (mp *MlannPlayer) Loss() float64 {
+	return mp.loss
 }
 
+// SetEpsilon sets the exploration rate of the player's explorer.
 func (mp *MlannPlayer) SetEpsilon(epsilon float64) {
 	mp.epsilon = epsilon
+	mp.explore.SetRate(epsilon)
+}
+
+// SetExplorer replaces the epsilon-greedy exploration the player starts
+// with.
+func (mp *MlannPlayer) SetExplorer(e Explorer) {
+	mp.explore = e
+}
+
+// SetLearningRate changes the learning rate of the network. The network is
+// rebuilt with the new rate and the weights copied over, so the optimizer
+// starts its moment estimates again; change it every few batches rather than
+// after every game.
+func (mp *MlannPlayer) SetLearningRate(alpha float64) {
+	if alpha == mp.cfg.Alpha {
+		return
+	}
+	var buf bytes.Buffer
+	mp.net.Write(&buf)
+	mp.cfg.Alpha = alpha
+	mp.net = newMlannNetwork(mp.cfg)
+	mp.net.Read(&buf)
+}
+
+// SetLookahead makes the player search ahead with la when it is not
+// exploring, nil restores the one ply greedy choice. A UCBExplorer picks
+// every move, so la has no effect with one.
+func (mp *MlannPlayer) SetLookahead(la *Lookahead) {
+	mp.lookahead = la
+}
+
+// SetTarget selects how training targets are computed. lambda is only used
+// by TDLambda.
+func (mp *MlannPlayer) SetTarget(mode TargetMode, lambda float64) {
+	mp.target = mode
+	mp.lambda = lambda
 }
 
 // Move selects the next move for the player based on the current state.
@@ -76,8 +215,10 @@ func (mp *MlannPlayer) Move(b Board) (mv *Move, err error) {
 	if err != nil {
 		return nil, err
 	}
-	if rand.Float64() < mp.epsilon {
-		mv, err = (&RandomPlayer{pid: mp.pid}).Move(b)
+	if i := mp.explore.Choose(b, moves, mp); i >= 0 {
+		mv = moves[i]
+	} else if mp.lookahead != nil {
+		mv, err = mp.lookahead.Move(b, mp.pid, mp)
 	} else {
 		X := MakePosition(b, moves[0])
 		out := mp.net.Forward(X)
@@ -95,21 +236,38 @@ func (mp *MlannPlayer) Move(b Board) (mv *Move, err error) {
 	return
 }
 
-func (mp *MlannPlayer) evalMove(b Board, mv *Move) float64 {
+// EvalMove returns the network's estimate of the value of mv on b.
+func (mp *MlannPlayer) EvalMove(b Board, mv *Move) float64 {
 	X := MakePosition(b, mv)
 	out := mp.net.Forward(X)
 	return out.Get(0, 0)
 }
 
 func (mp *MlannPlayer) Train(games []*GamePlayed) {
-	sample := makeSamples(mp.gamma, games, mp.pid, []float64{10.0, -10.0, 0.1})
+	mp.episodes += len(games)
+	mp.loss = math.NaN()
+	if mp.replay != nil {
+		mp.trainReplay(games)
+		return
+	}
+	targets := discount(mp.gamma)
+	if mp.target != MonteCarlo {
+		targets = mp.bootstrap
+	}
+	sample := makeSamples(games, mp.pid, []float64{10.0, -10.0, 0.1}, targets)
 	for i := 0; i < 1; i++ {
 		mp.net.Iterate(sample)
-		//yhat := mp.net.Forward(sample.X())
-		//diff := yhat.Sub(sample.Y())
-		//err := diff.Hadamard(diff).Sum()
-		//fmt.Println(i, math.Sqrt(err.Get(0, 0)))
 	}
+	mp.loss = sampleLoss(mp.net, sample)
+}
+
+// sampleLoss returns the mean over the columns of s of the squared error of
+// net.
+func sampleLoss(net *tensor.Network[float64], s *tensor.Sample[float64]) float64 {
+	yhat := net.Forward(s.X())
+	diff := yhat.Sub(s.Y())
+	err := diff.Hadamard(diff).Sum()
+	return err.Get(0, 0) / float64(s.Y().Shape()[1])
 }
 
 func convert(player int) (out string) {
@@ -128,19 +286,19 @@ func (mp *MlannPlayer) Display(b Board) {
 		z, _ := b.Get(i, 0)
 		zero := convert(z)
 		if zero == "" {
-			v := mp.evalMove(b, &Move{Pid: mp.pid, Row: i, Col: 0})
+			v := mp.EvalMove(b, &Move{Pid: mp.pid, Row: i, Col: 0})
 			zero = fmt.Sprintf("%.8f", v)
 		}
 		o, _ := b.Get(i, 1)
 		one := convert(o)
 		if one == "" {
-			v := mp.evalMove(b, &Move{Pid: mp.pid, Row: i, Col: 1})
+			v := mp.EvalMove(b, &Move{Pid: mp.pid, Row: i, Col: 1})
 			one = fmt.Sprintf("%.8f", v)
 		}
 		t, _ := b.Get(i, 2)
 		two := convert(t)
 		if two == "" {
-			v := mp.evalMove(b, &Move{Pid: mp.pid, Row: i, Col: 2})
+			v := mp.EvalMove(b, &Move{Pid: mp.pid, Row: i, Col: 2})
 			two = fmt.Sprintf("%.8f", v)
 		}
 		fmt.Printf("    %d    | %s | %s | %s\n", i, zero, one, two)
@@ -152,17 +310,18 @@ func (mp *MlannPlayer) Display(b Board) {
 	}
 }
 
-func (mp *MlannPlayer) Persist(path string) {
+func (mp *MlannPlayer) Persist(path string) error {
 	fmt.Println("saving network to file", path)
-	err := os.Remove(path)
-	if err != nil {
-		fmt.Println(err.Error())
-	}
-	f, err := os.Create(path)
-	if err != nil {
-		fmt.Println("error saving network for player1,", err.Error())
+	var payload bytes.Buffer
+	mp.net.Write(&payload)
+	h := ModelHeader{
+		Type:     "mlannplayer",
+		Pid:      mp.pid,
+		Arch:     &mp.cfg,
+		Hyper:    map[string]float64{"epsilon": mp.epsilon, "gamma": mp.gamma, "target": float64(mp.target), "lambda": mp.lambda},
+		Episodes: mp.episodes,
 	}
-	mp.net.Write(f)
+	return saveModel(path, h, payload.Bytes())
 }
 
 // rewards is a 3 element slice 0: win, 1: loss, 2: draw
@@ -214,7 +373,10 @@ func (mp *MlannPlayer) Persist(path string) {
 // iteratively improving the agent, as opposed to applying them all at once through
 // a Mini batch process. Unless, we're executing in an off-policy approach where
 // we accumulate games and then build training samples and execute one update.
-func makeSamples(gamma float64, g []*GamePlayed, pid int, rewards []float64) (out *tensor.Sample[float64]) {
+//
+// targets converts the player's moves in a game and the final reward into
+// one regression target per move.
+func makeSamples(g []*GamePlayed, pid int, rewards []float64, targets func(gp *GamePlayed, reward float64) []float64) (out *tensor.Sample[float64]) {
 	for i := range g {
 		// get the positions from the game for our pid
 		gp := NewGamePlayed()
@@ -229,27 +391,8 @@ func makeSamples(gamma float64, g []*GamePlayed, pid int, rewards []float64) (ou
 			gp.Append(g[i].Positions()[j])
 		}
 
-		//compute the final reward from the outcome for a win, loss, or tie
-		reward := float64(0.0)
-		if g[i].outcome > 0 && g[i].outcome == float64(pid) {
-			// win
-			reward = rewards[0] / float64(len(gp.Positions()))
-		}
-		if g[i].outcome > 0 && g[i].outcome != float64(pid) {
-			// loss
-			reward = rewards[1]
-		}
-		if g[i].outcome == -1 {
-			// tie
-			reward = rewards[2]
-		}
-
-		// discount rewards back in time based on our expected update
-		rewards := make([]float64, len(gp.Positions()))
-		for j := len(gp.Positions()) - 1; j >= 0; j-- {
-			reward *= gamma
-			rewards[j] = reward
-		}
+		reward := finalReward(g[i], pid, rewards, len(gp.Positions()))
+		rewards := targets(gp, reward)
 		if out == nil {
 			out = gp.ToSample(rewards)
 			if false {
@@ -271,6 +414,121 @@ func makeSamples(gamma float64, g []*GamePlayed, pid int, rewards []float64) (ou
 	return
 }
 
+// finalReward computes the reward for pid from the outcome of g for a win,
+// loss, or tie. Wins are shared out over the moves the player made so that
+// quicker wins are worth more.
+func finalReward(g *GamePlayed, pid int, rewards []float64, moves int) float64 {
+	reward := float64(0.0)
+	if g.outcome > 0 && g.outcome == float64(pid) {
+		// win
+		reward = rewards[0] / float64(moves)
+	}
+	if g.outcome > 0 && g.outcome != float64(pid) {
+		// loss
+		reward = rewards[1]
+	}
+	if g.outcome == -1 {
+		// tie
+		reward = rewards[2]
+	}
+	return reward
+}
+
+// discount returns the Monte Carlo targets, the final reward discounted
+// back in time by gamma for every move.
+func discount(gamma float64) func(gp *GamePlayed, reward float64) []float64 {
+	return func(gp *GamePlayed, reward float64) []float64 {
+		rewards := make([]float64, len(gp.Positions()))
+		for j := len(gp.Positions()) - 1; j >= 0; j-- {
+			reward *= gamma
+			rewards[j] = reward
+		}
+		return rewards
+	}
+}
+
+// bootstrap computes the targets for the temporal difference modes. gp holds
+// only this player's moves so position t+1 is the board and move the next
+// time the player moved. Intermediate rewards are 0 and the last move is
+// always trained towards the final reward discounted once, as in discount,
+// since the game ends after it. The targets are on the same scale as the
+// Monte Carlo ones and TDLambda with lambda 1 gives exactly those.
+// TDLambda is computed with the backward recursion
+//
+// G_t = gamma*[(1-lambda)*Q(s_{t+1}, a_{t+1}) + lambda*G_{t+1}]
+//
+// which gives the same targets as accumulating eligibility traces over the
+// game and applying them offline.
+func (mp *MlannPlayer) bootstrap(gp *GamePlayed, reward float64) []float64 {
+	positions := gp.Positions()
+	targets := make([]float64, len(positions))
+	if len(positions) == 0 {
+		return targets
+	}
+	last := len(positions) - 1
+	targets[last] = mp.gamma * reward
+	for t := last - 1; t >= 0; t-- {
+		next := positions[t+1]
+		switch mp.target {
+		case TD0:
+			targets[t] = mp.gamma * mp.expected(next)
+		case SARSA:
+			targets[t] = mp.gamma * mp.net.Forward(next).Get(0, 0)
+		case QLearning:
+			targets[t] = mp.gamma * mp.greedy(next)
+		case TDLambda:
+			q := mp.net.Forward(next).Get(0, 0)
+			targets[t] = mp.gamma * ((1-mp.lambda)*q + mp.lambda*targets[t+1])
+		}
+	}
+	return targets
+}
+
+// values evaluates every valid move from the board that p was played on.
+func (mp *MlannPlayer) values(p Position) []float64 {
+	g, _, _ := decode(p)
+	b := g.board()
+	moves, err := ValidMoves(b, mp.pid)
+	if err != nil {
+		return nil
+	}
+	out := make([]float64, len(moves))
+	for i := range moves {
+		out[i] = mp.EvalMove(b, moves[i])
+	}
+	return out
+}
+
+// greedy is max_a Q(s, a) for the board that p was played on.
+func (mp *MlannPlayer) greedy(p Position) float64 {
+	values := mp.values(p)
+	if len(values) == 0 {
+		return 0
+	}
+	out := values[0]
+	for _, v := range values[1:] {
+		out = math.Max(out, v)
+	}
+	return out
+}
+
+// expected is E[Q(s, a)] for the board that p was played on when a is
+// chosen epsilon-greedily, the greedy move with probability 1-epsilon and a
+// uniformly random move otherwise.
+func (mp *MlannPlayer) expected(p Position) float64 {
+	values := mp.values(p)
+	if len(values) == 0 {
+		return 0
+	}
+	mean, max := 0.0, values[0]
+	for _, v := range values {
+		mean += v
+		max = math.Max(max, v)
+	}
+	mean /= float64(len(values))
+	return (1-mp.epsilon)*max + mp.epsilon*mean
+}
+
 func TDError[T tensor.Numeric](yhat, y *tensor.Tensor[T]) *tensor.Tensor[T] {
 	return y.Sub(yhat)
 }
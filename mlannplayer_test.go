@@ -2,6 +2,7 @@ package tictactoe
 
 import (
 	"fmt"
+	"math"
 	"testing"
 )
 
@@ -23,10 +24,74 @@ func TestNetworkValue(t *testing.T) {
 			{2, 2, 0},
 		}},
 	}
-	player := NewMlannPlayer(1, "./game/player1.net", 0.0, 0.9)
+	player := NewMlannPlayer(1, 0.0, 0.9)
+	if err := player.Load("./game/player1.net"); err != nil {
+		t.Skip("no trained network to display,", err.Error())
+	}
 	for i := range boards {
 		fmt.Println("Board", i)
 		boards[i].Display()
 		player.Display(boards[i])
 	}
 }
+
+func TestBootstrapTargets(t *testing.T) {
+	b := NewBoard()
+	b.Reset()
+	for _, mv := range []*Move{{1, 0, 0}, {2, 0, 1}, {1, 1, 1}, {2, 2, 2}, {1, 2, 0}, {2, 1, 0}, {1, 0, 2}} {
+		if err := b.Move(mv); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	gp := NewGamePlayed()
+	for j := 0; j < len(b.GamePlayed().Positions()); j += 2 {
+		gp.Append(b.GamePlayed().Positions()[j])
+	}
+	positions := gp.Positions()
+
+	mp := NewMlannPlayer(1, 0.0, 0.9)
+	q := func(i int) float64 {
+		return mp.net.Forward(positions[i]).Get(0, 0)
+	}
+
+	mc := discount(0.9)(gp, 10)
+	for j := range mc {
+		want := 10 * math.Pow(0.9, float64(len(mc)-j))
+		if math.Abs(mc[j]-want) > 1e-9 {
+			t.Errorf("montecarlo target %d: expected %.5f, got %.5f", j, want, mc[j])
+		}
+	}
+
+	mp.SetTarget(SARSA, 0)
+	sarsa := mp.bootstrap(gp, 10)
+	for j := 0; j < len(sarsa)-1; j++ {
+		if want := 0.9 * q(j+1); math.Abs(sarsa[j]-want) > 1e-9 {
+			t.Errorf("sarsa target %d: expected %.5f, got %.5f", j, want, sarsa[j])
+		}
+	}
+	if last := sarsa[len(sarsa)-1]; math.Abs(last-9) > 1e-9 {
+		t.Errorf("expected the last move to target the discounted final reward, got %.5f", last)
+	}
+
+	mp.SetTarget(QLearning, 0)
+	ql := mp.bootstrap(gp, 10)
+	for j := 0; j < len(ql)-1; j++ {
+		if ql[j] < sarsa[j]-1e-9 {
+			t.Errorf("q-learning target %d is below the sarsa target", j)
+		}
+	}
+
+	// lambda 0 is sarsa and lambda 1 is monte carlo
+	mp.SetTarget(TDLambda, 0)
+	for j, v := range mp.bootstrap(gp, 10) {
+		if math.Abs(v-sarsa[j]) > 1e-9 {
+			t.Errorf("td(0) target %d: expected %.5f, got %.5f", j, sarsa[j], v)
+		}
+	}
+	mp.SetTarget(TDLambda, 1)
+	for j, v := range mp.bootstrap(gp, 10) {
+		if math.Abs(v-mc[j]) > 1e-9 {
+			t.Errorf("td(1) target %d: expected %.5f, got %.5f", j, mc[j], v)
+		}
+	}
+}
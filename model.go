@@ -0,0 +1,236 @@
+package tictactoe
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Model files start with modelMagic and a version, followed by the length of
+// a JSON ModelHeader, the header and then the tensor dumps of the player's
+// networks, the payload. The header holds a sha256 of the payload so a
+// truncated or damaged file is caught before any weights are read.
+//
+//	magic    8 bytes  "TTTMODEL"
+//	version  uint16   big endian
+//	length   uint32   big endian, length of the header
+//	header   JSON ModelHeader
+//	payload  network weights
+const (
+	modelMagic   = "TTTMODEL"
+	modelVersion = 1
+)
+
+// ModelHeader describes the player a model file was saved from.
+type ModelHeader struct {
+	// Type is the player type, e.g. mlannplayer.
+	Type string `json:"type"`
+	Pid  int    `json:"pid"`
+	// Arch is the network config for players that have one.
+	Arch *NetConfig `json:"arch,omitempty"`
+	// Hyper holds the player's hyperparameters at the time it was saved.
+	Hyper map[string]float64 `json:"hyper,omitempty"`
+	// Episodes is the number of games the player has trained on.
+	Episodes int       `json:"episodes"`
+	Saved    time.Time `json:"saved"`
+	Checksum string    `json:"checksum"`
+}
+
+// ModelErrorKind says what is wrong with a model file.
+type ModelErrorKind int
+
+const (
+	// ModelCorrupt is a file that is truncated, has an unreadable header or
+	// whose payload does not match its checksum.
+	ModelCorrupt ModelErrorKind = iota
+	// ModelVersion is a file written by a newer version of the format.
+	ModelVersion
+	// ModelWrongType is a file saved by a different type of player.
+	ModelWrongType
+	// ModelMismatch is a file whose networks do not fit the player.
+	ModelMismatch
+	// ModelLegacy is a file saved before model files had a header.
+	ModelLegacy
+	// ModelWrongSide is a file trained to play as the other player.
+	ModelWrongSide
+)
+
+func (k ModelErrorKind) String() string {
+	switch k {
+	case ModelCorrupt:
+		return "corrupt"
+	case ModelVersion:
+		return "unsupported version"
+	case ModelWrongType:
+		return "wrong player type"
+	case ModelMismatch:
+		return "mismatched"
+	case ModelLegacy:
+		return "no header"
+	case ModelWrongSide:
+		return "wrong side"
+	}
+	return "unknown"
+}
+
+// ModelError is returned when a model file cannot be loaded into a player.
+type ModelError struct {
+	Path   string
+	Kind   ModelErrorKind
+	Detail string
+}
+
+func (e *ModelError) Error() string {
+	return fmt.Sprintf("model %s: %s: %s", e.Path, e.Kind, e.Detail)
+}
+
+// WrongSide reports whether err is a ModelError for a model trained to play
+// as the other player, so the player can only take the other side.
+func WrongSide(err error) bool {
+	var me *ModelError
+	return errors.As(err, &me) && me.Kind == ModelWrongSide
+}
+
+// writeModel writes the header h and payload to w, filling in the checksum
+// and the time saved.
+func writeModel(w io.Writer, h ModelHeader, payload []byte) error {
+	sum := sha256.Sum256(payload)
+	h.Checksum = hex.EncodeToString(sum[:])
+	h.Saved = time.Now().UTC()
+	header, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	buf.WriteString(modelMagic)
+	binary.Write(&buf, binary.BigEndian, uint16(modelVersion))
+	binary.Write(&buf, binary.BigEndian, uint32(len(header)))
+	buf.Write(header)
+	buf.Write(payload)
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// saveModel writes a model file to path, see WriteFileAtomic.
+func saveModel(path string, h ModelHeader, payload []byte) error {
+	return WriteFileAtomic(path, func(w io.Writer) error {
+		return writeModel(w, h, payload)
+	})
+}
+
+// WriteFileAtomic replaces the file at path with what write writes. It
+// writes to a temporary file in the same directory and renames it over path
+// once it is complete, so a crash or error part way through leaves the old
+// file as it was.
+func WriteFileAtomic(path string, write func(w io.Writer) error) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	// temporary files are private, give the model the mode of the file it
+	// replaces or the usual one for a new file
+	mode := fs.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := f.Chmod(mode); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// readModel parses a model file held in data. Files without the magic were
+// written before the format existed, they are returned whole as the payload
+// with legacy set so the caller can fall back to the old layout.
+func readModel(path string, data []byte) (h ModelHeader, payload []byte, legacy bool, err error) {
+	if !bytes.HasPrefix(data, []byte(modelMagic)) {
+		return h, data, true, nil
+	}
+	rest := data[len(modelMagic):]
+	if len(rest) < 6 {
+		return h, nil, false, &ModelError{Path: path, Kind: ModelCorrupt, Detail: "truncated header"}
+	}
+	version := binary.BigEndian.Uint16(rest)
+	if version > modelVersion {
+		return h, nil, false, &ModelError{Path: path, Kind: ModelVersion, Detail: fmt.Sprintf("version %d, expected at most %d", version, modelVersion)}
+	}
+	n := int(binary.BigEndian.Uint32(rest[2:]))
+	rest = rest[6:]
+	if len(rest) < n {
+		return h, nil, false, &ModelError{Path: path, Kind: ModelCorrupt, Detail: "truncated header"}
+	}
+	if err := json.Unmarshal(rest[:n], &h); err != nil {
+		return h, nil, false, &ModelError{Path: path, Kind: ModelCorrupt, Detail: err.Error()}
+	}
+	payload = rest[n:]
+	sum := sha256.Sum256(payload)
+	if hex.EncodeToString(sum[:]) != h.Checksum {
+		return h, nil, false, &ModelError{Path: path, Kind: ModelCorrupt, Detail: "checksum does not match, the file is damaged or truncated"}
+	}
+	return h, payload, false, nil
+}
+
+// openModel reads the model file at path for a player of type typ.
+func openModel(path, typ string) (h ModelHeader, payload []byte, legacy bool, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return h, nil, false, err
+	}
+	h, payload, legacy, err = readModel(path, data)
+	if err != nil || legacy {
+		return
+	}
+	if h.Type != typ {
+		return h, nil, false, &ModelError{Path: path, Kind: ModelWrongType, Detail: fmt.Sprintf("saved by a %s, expected a %s", h.Type, typ)}
+	}
+	if h.Arch != nil {
+		if err := h.Arch.Validate(); err != nil {
+			return h, nil, false, &ModelError{Path: path, Kind: ModelCorrupt, Detail: err.Error()}
+		}
+	}
+	return
+}
+
+// ReadModelHeader returns the header of the model file at path without
+// loading it into a player.
+func ReadModelHeader(path string) (ModelHeader, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ModelHeader{}, err
+	}
+	h, _, legacy, err := readModel(path, data)
+	if err == nil && legacy {
+		err = &ModelError{Path: path, Kind: ModelLegacy, Detail: "saved before model files were versioned"}
+	}
+	return h, err
+}
@@ -0,0 +1,195 @@
+package tictactoe
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestModelRoundTrip(t *testing.T) {
+	cfg := DefaultNetConfig()
+	h := ModelHeader{Type: "mlannplayer", Pid: 2, Arch: &cfg, Hyper: map[string]float64{"gamma": 0.9}, Episodes: 120}
+	var buf bytes.Buffer
+	if err := writeModel(&buf, h, []byte("weights")); err != nil {
+		t.Fatal(err.Error())
+	}
+	out, payload, legacy, err := readModel("test", buf.Bytes())
+	if err != nil || legacy {
+		t.Fatalf("expected a versioned model, got %v %v", legacy, err)
+	}
+	if string(payload) != "weights" {
+		t.Errorf("expected the payload back, got %q", payload)
+	}
+	if out.Type != h.Type || out.Pid != h.Pid || out.Episodes != h.Episodes || out.Hyper["gamma"] != 0.9 || !out.Arch.Equal(cfg) {
+		t.Errorf("expected %+v, got %+v", h, out)
+	}
+	if out.Saved.IsZero() || out.Checksum == "" {
+		t.Errorf("expected the time saved and checksum to be filled in, got %+v", out)
+	}
+
+	_, payload, legacy, err = readModel("test", []byte("weights"))
+	if err != nil || !legacy || string(payload) != "weights" {
+		t.Errorf("expected a legacy file to be returned whole, got %q %v %v", payload, legacy, err)
+	}
+}
+
+func TestModelErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeModel(&buf, ModelHeader{Type: "gruplayer"}, []byte("weights")); err != nil {
+		t.Fatal(err.Error())
+	}
+	good := buf.Bytes()
+
+	damaged := append([]byte{}, good...)
+	damaged[len(damaged)-1] ^= 0xff
+	newer := append([]byte{}, good...)
+	newer[len(modelMagic)+1] = modelVersion + 1
+
+	type test struct {
+		data []byte
+		kind ModelErrorKind
+	}
+	tests := []test{
+		{data: damaged, kind: ModelCorrupt},
+		{data: good[:len(good)-3], kind: ModelCorrupt},
+		{data: good[:len(modelMagic)+8], kind: ModelCorrupt},
+		{data: good[:len(modelMagic)+2], kind: ModelCorrupt},
+		{data: newer, kind: ModelVersion},
+	}
+	for i := range tests {
+		_, _, _, err := readModel("test", tests[i].data)
+		var me *ModelError
+		if !errors.As(err, &me) || me.Kind != tests[i].kind {
+			t.Errorf("test %d: expected a %s error, got %v", i, tests[i].kind, err)
+		}
+	}
+}
+
+func TestModelRejectsWrongPlayer(t *testing.T) {
+	dir := t.TempDir()
+	gru := filepath.Join(dir, "gru.net")
+	if err := NewGruPlayer(1, 0).Persist(gru); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	cfg := DefaultNetConfig()
+	cfg.Layers = []int{8}
+	mp := NewMlannPlayerConfig(1, 0, 0.9, cfg)
+	err := mp.Load(gru)
+	var me *ModelError
+	if !errors.As(err, &me) || me.Kind != ModelWrongType {
+		t.Errorf("expected a wrong type error, got %v", err)
+	}
+	if !mp.Config().Equal(cfg) {
+		t.Errorf("a rejected file changed the network config to %+v", mp.Config())
+	}
+
+	mlann := filepath.Join(dir, "player1.net")
+	if err := NewMlannPlayer(1, 0, 0.9).Persist(mlann); err != nil {
+		t.Fatal(err.Error())
+	}
+	err = NewMlannPlayer(2, 0, 0.9).Load(mlann)
+	if !WrongSide(err) {
+		t.Errorf("expected a wrong side error loading player 1 as player 2, got %v", err)
+	}
+
+	gp := NewGruPlayer(2, 0)
+	err = gp.Load(gru)
+	if !WrongSide(err) {
+		t.Errorf("expected a wrong side error loading gru player 1 as player 2, got %v", err)
+	}
+
+	policy := filepath.Join(dir, "policy.net")
+	if err := NewPolicyPlayer(1, 0.9, true).Persist(policy); err != nil {
+		t.Fatal(err.Error())
+	}
+	err = NewPolicyPlayer(1, 0.9, false).Load(policy)
+	if !errors.As(err, &me) || me.Kind != ModelMismatch {
+		t.Errorf("expected a mismatch error, got %v", err)
+	}
+	if err := NewPolicyPlayer(1, 0.9, true).Load(policy); err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+	}
+
+	h, err := ReadModelHeader(policy)
+	if err != nil || h.Type != "policyplayer" || h.Hyper["baseline"] != 1 {
+		t.Errorf("unexpected header %+v %v", h, err)
+	}
+}
+
+func TestModelLegacyMlann(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "legacy.net")
+	var buf bytes.Buffer
+	newMlannNetwork(DefaultNetConfig()).Write(&buf)
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err.Error())
+	}
+	mp := NewMlannPlayer(1, 0, 0.9)
+	if err := mp.Load(path); err != nil {
+		t.Errorf("expected an unversioned network to load, got %s", err.Error())
+	}
+	_, err := ReadModelHeader(path)
+	var me *ModelError
+	if !errors.As(err, &me) || me.Kind != ModelLegacy {
+		t.Errorf("expected a legacy error, got %v", err)
+	}
+}
+
+func TestPersistAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "player1.net")
+	mp := NewMlannPlayer(1, 0, 0.9)
+	if err := mp.Persist(path); err != nil {
+		t.Fatal(err.Error())
+	}
+	before, _ := os.ReadFile(path)
+	if info, err := os.Stat(path); err != nil {
+		t.Error(err.Error())
+	} else if info.Mode().Perm() != 0644 {
+		t.Errorf("expected a saved network readable by everyone, got %v", info.Mode())
+	}
+
+	// a failed write leaves the saved network and no temporary files behind
+	failed := errors.New("disk full")
+	err := WriteFileAtomic(path, func(w io.Writer) error {
+		w.Write([]byte("half a network"))
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Errorf("expected the write error, got %v", err)
+	}
+	after, _ := os.ReadFile(path)
+	if !bytes.Equal(before, after) {
+		t.Errorf("a failed write changed the saved network")
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected only the saved network in %s, found %d files", dir, len(entries))
+	}
+
+	if err := mp.Load(filepath.Join(dir, "missing.net")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected a missing file error, got %v", err)
+	}
+	if err := mp.Persist(filepath.Join(dir, "missing", "player1.net")); err == nil {
+		t.Errorf("expected an error saving into a missing directory")
+	}
+}
+
+func TestModelRejectsHeaderless(t *testing.T) {
+	// a q-table has no model header, it must not load as network weights
+	path := filepath.Join(t.TempDir(), "qtable.json")
+	if err := NewQTablePlayer(1, 0, 0.5, 0.9).Persist(path); err != nil {
+		t.Fatal(err.Error())
+	}
+	var me *ModelError
+	if err := NewAlphaZeroPlayer(1, 100, 1.4, 0).Load(path); !errors.As(err, &me) || me.Kind != ModelLegacy {
+		t.Errorf("expected an alphazero player to refuse a file without a header, got %v", err)
+	}
+	if err := NewPolicyPlayer(1, 0.9, true).Load(path); !errors.As(err, &me) || me.Kind != ModelLegacy {
+		t.Errorf("expected a policy player to refuse a file without a header, got %v", err)
+	}
+}
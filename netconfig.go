@@ -0,0 +1,151 @@
+package tictactoe
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"bigfunbrewing.com/tensor"
+)
+
+// NetConfig describes the topology and optimizer of an MlannPlayer network.
+// The input is always a position of 18 values and the output a single
+// value, Layers lists the widths of the layers in between.
+type NetConfig struct {
+	Layers []int `json:"layers"`
+	// Activation is the activation of the hidden layers and Output the
+	// activation of the output layer. One of {leakyrelu, linear}.
+	Activation string  `json:"activation"`
+	Output     string  `json:"output"`
+	Leak       float64 `json:"leak"`
+	Optimizer  string  `json:"optimizer"`
+	Alpha      float64 `json:"alpha"`
+	Lambda     float64 `json:"lambda"`
+	Batch      int     `json:"batch"`
+}
+
+// DefaultNetConfig is the network MlannPlayer has always used,
+// 18→36→36→36→18→1 with leaky ReLU throughout and Adam.
+func DefaultNetConfig() NetConfig {
+	return NetConfig{
+		Layers:     []int{36, 36, 36, 18},
+		Activation: "leakyrelu",
+		Output:     "leakyrelu",
+		Leak:       0.1,
+		Optimizer:  "adam",
+		Alpha:      0.05,
+		Lambda:     0.3,
+		Batch:      50,
+	}
+}
+
+// LoadNetConfig reads a NetConfig from a JSON file. Fields missing from the
+// file keep their default values.
+func LoadNetConfig(path string) (NetConfig, error) {
+	cfg := DefaultNetConfig()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("reading network config %s: %w", path, err)
+	}
+	return cfg, cfg.Validate()
+}
+
+// ParseLayers converts a comma separated list of layer widths, e.g.
+// "36,36,18", into a slice.
+func ParseLayers(s string) ([]int, error) {
+	out := make([]int, 0)
+	for _, w := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(w))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("bad layer width %q in %q", w, s)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+// Validate reports whether the config describes a network that can be built.
+func (cfg NetConfig) Validate() error {
+	if len(cfg.Layers) == 0 {
+		return fmt.Errorf("network config has no hidden layers")
+	}
+	for _, w := range cfg.Layers {
+		if w <= 0 {
+			return fmt.Errorf("network config has a layer of width %d", w)
+		}
+	}
+	for _, a := range []string{cfg.Activation, cfg.Output} {
+		if _, err := activation(a, cfg.Leak); err != nil {
+			return err
+		}
+	}
+	if cfg.Optimizer == "" {
+		return fmt.Errorf("network config has no optimizer")
+	}
+	if cfg.Batch <= 0 {
+		return fmt.Errorf("network config has a batch size of %d", cfg.Batch)
+	}
+	return nil
+}
+
+// Equal reports whether two configs describe the same network.
+func (cfg NetConfig) Equal(o NetConfig) bool {
+	return reflect.DeepEqual(cfg, o)
+}
+
+func activation(name string, leak float64) (tensor.Activation[float64], error) {
+	switch name {
+	case "leakyrelu":
+		return tensor.LeakyRelu[float64]{Leak: leak}, nil
+	case "linear":
+		return tensor.Linear[float64]{}, nil
+	}
+	return nil, fmt.Errorf("unknown activation %q", name)
+}
+
+// newMlannNetwork builds an untrained network described by cfg, which must
+// be valid.
+func newMlannNetwork(cfg NetConfig) *tensor.Network[float64] {
+	hidden, _ := activation(cfg.Activation, cfg.Leak)
+	output, _ := activation(cfg.Output, cfg.Leak)
+	layers := make([]tensor.Layer[float64], 0, len(cfg.Layers)+1)
+	in := 18
+	for _, w := range cfg.Layers {
+		layers = append(layers, tensor.NewDense[float64](in, w, 1.0, 0.1, hidden, cfg.Optimizer, cfg.Alpha, cfg.Lambda))
+		in = w
+	}
+	layers = append(layers, tensor.NewDense[float64](in, 1, 1.0, 0.1, output, cfg.Optimizer, cfg.Alpha, cfg.Lambda))
+	return tensor.NewNetwork(
+		tensor.SquaredError[float64],
+		tensor.SquaredErrorPrime[float64],
+		cfg.Batch,
+		layers...,
+	)
+}
+
+// readNetConfig reads the config from the start of an unversioned network
+// file, where it was saved as a single line of JSON ahead of the weights.
+// Files saved before configs were stored start directly with the weights,
+// those are reported with ok false and r is left at the start of the weights.
+func readNetConfig(r *bufio.Reader) (cfg NetConfig, ok bool, err error) {
+	b, err := r.Peek(1)
+	if err != nil || b[0] != '{' {
+		return DefaultNetConfig(), false, nil
+	}
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		return cfg, false, err
+	}
+	cfg = DefaultNetConfig()
+	if err = json.Unmarshal(line, &cfg); err != nil {
+		return cfg, false, err
+	}
+	return cfg, true, cfg.Validate()
+}
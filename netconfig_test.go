@@ -0,0 +1,111 @@
+package tictactoe
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseLayers(t *testing.T) {
+	layers, err := ParseLayers("36, 36,18")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(layers) != 3 || layers[0] != 36 || layers[1] != 36 || layers[2] != 18 {
+		t.Errorf("expected [36 36 18], got %v", layers)
+	}
+	for _, s := range []string{"", "36,,18", "36,-1", "wide"} {
+		if _, err := ParseLayers(s); err == nil {
+			t.Errorf("expected an error for %q", s)
+		}
+	}
+}
+
+func TestNetConfigValidate(t *testing.T) {
+	if err := DefaultNetConfig().Validate(); err != nil {
+		t.Errorf("default config is invalid: %s", err.Error())
+	}
+	bad := []func(*NetConfig){
+		func(c *NetConfig) { c.Layers = nil },
+		func(c *NetConfig) { c.Layers = []int{36, 0} },
+		func(c *NetConfig) { c.Activation = "tanh" },
+		func(c *NetConfig) { c.Output = "" },
+		func(c *NetConfig) { c.Optimizer = "" },
+		func(c *NetConfig) { c.Batch = 0 },
+	}
+	for i := range bad {
+		cfg := DefaultNetConfig()
+		bad[i](&cfg)
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("test %d: expected an error for %+v", i, cfg)
+		}
+	}
+}
+
+func TestNetConfigStored(t *testing.T) {
+	cfg := DefaultNetConfig()
+	cfg.Layers = []int{24, 12}
+	cfg.Output = "linear"
+
+	var buf bytes.Buffer
+	data, _ := json.Marshal(cfg)
+	buf.Write(data)
+	buf.WriteString("\nweights")
+	r := bufio.NewReader(&buf)
+	out, ok, err := readNetConfig(r)
+	if err != nil || !ok {
+		t.Fatalf("expected a stored config, got %v %v", ok, err)
+	}
+	if !out.Equal(cfg) {
+		t.Errorf("expected %+v, got %+v", cfg, out)
+	}
+	if rest, _ := r.ReadString(0); rest != "weights" {
+		t.Errorf("expected the weights to follow the config, got %q", rest)
+	}
+
+	// files saved before configs were stored hold only the weights
+	r = bufio.NewReader(bytes.NewBufferString("weights"))
+	out, ok, err = readNetConfig(r)
+	if err != nil || ok || !out.Equal(DefaultNetConfig()) {
+		t.Errorf("expected the default config for a legacy file, got %+v %v %v", out, ok, err)
+	}
+	if rest, _ := r.ReadString(0); rest != "weights" {
+		t.Errorf("expected the weights to be left unread, got %q", rest)
+	}
+}
+
+func TestMlannPlayerConfig(t *testing.T) {
+	dir := t.TempDir()
+	cfg := DefaultNetConfig()
+	cfg.Layers = []int{8}
+	cfg.Alpha = 0.01
+	path := filepath.Join(dir, "player1.net")
+	NewMlannPlayerConfig(1, 0, 0.9, cfg).Persist(path)
+
+	// the saved config wins over the one asked for
+	mp := NewMlannPlayer(1, 0, 0.9)
+	if err := mp.Load(path); err != nil {
+		t.Fatal(err.Error())
+	}
+	if !mp.Config().Equal(cfg) {
+		t.Errorf("expected the saved config %+v, got %+v", cfg, mp.Config())
+	}
+
+	spec := filepath.Join(dir, "arch.json")
+	if err := os.WriteFile(spec, []byte(`{"layers": [16, 8], "output": "linear"}`), 0644); err != nil {
+		t.Fatal(err.Error())
+	}
+	loaded, err := LoadNetConfig(spec)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	want := DefaultNetConfig()
+	want.Layers = []int{16, 8}
+	want.Output = "linear"
+	if !loaded.Equal(want) {
+		t.Errorf("expected %+v, got %+v", want, loaded)
+	}
+}
@@ -15,7 +15,12 @@ type Player interface {
 	Move(b Board) (mv *Move, err error)
 	Train(sample []*GamePlayed)
 	Display(b Board)
-	Persist(path string)
+	// Persist saves what the player has learned to path, replacing the file
+	// only once the new one is completely written.
+	Persist(path string) error
+	// Load replaces what the player has learned with the state saved at
+	// path. On error the player is left unchanged.
+	Load(path string) error
 }
 
 type RandomPlayer struct {
@@ -59,8 +64,14 @@ func (rp *RandomPlayer) Train(games []*GamePlayed) {
 	//do nothing
 }
 
-func (rp *RandomPlayer) Persist(path string) {
+func (rp *RandomPlayer) Persist(path string) error {
 	//do nothing
+	return nil
+}
+
+func (rp *RandomPlayer) Load(path string) error {
+	//do nothing
+	return nil
 }
 
 func (rp *RandomPlayer) Display(b Board) {
@@ -131,8 +142,12 @@ func (hp *HumanPlayer) Train(games []*GamePlayed) {
 
 }
 
-func (hp *HumanPlayer) Persist(path string) {
+func (hp *HumanPlayer) Persist(path string) error {
+	return nil
+}
 
+func (hp *HumanPlayer) Load(path string) error {
+	return nil
 }
 
 func (hp *HumanPlayer) Display(b Board) {
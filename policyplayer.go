@@ -0,0 +1,242 @@
+package tictactoe
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/rand"
+
+	"bigfunbrewing.com/tensor"
+)
+
+// PolicyPlayer learns a policy directly instead of a value for each move. Its
+// actor network reads the board from the player's point of view and outputs a
+// logit for each of the nine cells, occupied cells are masked out and the
+// rest go through a softmax that the player samples its move from.
+//
+// Train uses REINFORCE. With a baseline the player also trains a critic
+// network that estimates the value of each board and subtracts it from the
+// return, making it an actor-critic learner with lower variance updates.
+//
+// The tensor networks only train towards targets, so the policy gradient is
+// applied through the targets. For a squared error loss the gradient with
+// respect to the outputs is yhat - y, so training the logits towards
+//
+// logits + step*A*(onehot(a) - pi)
+//
+// moves them along A*grad log pi(a|s), the REINFORCE direction, where A is
+// the return less the baseline.
+type PolicyPlayer struct {
+	pid   int
+	gamma float64
+	// step scales the policy gradient written into the logit targets
+	step    float64
+	rewards []float64
+	actor   *tensor.Network[float64]
+	critic  *tensor.Network[float64]
+	// episodes is the number of games the networks have trained on
+	episodes int
+}
+
+// NewPolicyPlayer returns a policy gradient player, with a learned baseline
+// when baseline is true. Call Load to play saved networks.
+func NewPolicyPlayer(pid int, gamma float64, baseline bool) *PolicyPlayer {
+	alpha := 0.01
+	lambda := 0.3
+	pp := &PolicyPlayer{
+		pid:     pid,
+		gamma:   gamma,
+		step:    1.0,
+		rewards: []float64{1.0, -1.0, 0.0},
+		actor: tensor.NewNetwork(
+			tensor.SquaredError[float64],
+			tensor.SquaredErrorPrime[float64],
+			50,
+			tensor.NewDense[float64](9, 36, 1.0, 0.1, tensor.LeakyRelu[float64]{Leak: 0.1}, "adam", alpha, lambda),
+			tensor.NewDense[float64](36, 36, 1.0, 0.1, tensor.LeakyRelu[float64]{Leak: 0.1}, "adam", alpha, lambda),
+			tensor.NewDense[float64](36, 9, 1.0, 0.1, tensor.Linear[float64]{}, "adam", alpha, lambda),
+		),
+	}
+	if baseline {
+		pp.critic = tensor.NewNetwork(
+			tensor.SquaredError[float64],
+			tensor.SquaredErrorPrime[float64],
+			50,
+			tensor.NewDense[float64](9, 36, 1.0, 0.1, tensor.LeakyRelu[float64]{Leak: 0.1}, "adam", alpha, lambda),
+			tensor.NewDense[float64](36, 1, 1.0, 0.1, tensor.Linear[float64]{}, "adam", alpha, lambda),
+		)
+	}
+	return pp
+}
+
+// Load reads the player's networks from the model file at path. A file saved
+// with a critic only loads into a player with one and the other way round,
+// files without a model header are refused.
+func (pp *PolicyPlayer) Load(path string) error {
+	h, payload, legacy, err := openModel(path, "policyplayer")
+	if err != nil {
+		return err
+	}
+	if legacy {
+		// policy players have always saved a header
+		return &ModelError{Path: path, Kind: ModelLegacy, Detail: "no model header, not a policyplayer file"}
+	}
+	if (h.Hyper["baseline"] == 1) != (pp.critic != nil) {
+		return &ModelError{Path: path, Kind: ModelMismatch, Detail: "saved with a different -baseline setting"}
+	}
+	r := bytes.NewReader(payload)
+	pp.actor.Read(r)
+	if pp.critic != nil {
+		pp.critic.Read(r)
+	}
+	pp.episodes = h.Episodes
+	return nil
+}
+
+// policy returns the logits and the move probabilities for pid on g. Cells
+// that are occupied have probability 0.
+func (pp *PolicyPlayer) policy(g grid) (logits, pi [9]float64) {
+	out := pp.actor.Forward(g.perspective(pp.pid))
+	empty := g.empty()
+	max := math.Inf(-1)
+	for i := range logits {
+		logits[i] = out.Get(i, 0)
+	}
+	for _, idx := range empty {
+		max = math.Max(max, logits[idx])
+	}
+	sum := 0.0
+	for _, idx := range empty {
+		pi[idx] = math.Exp(logits[idx] - max)
+		sum += pi[idx]
+	}
+	for _, idx := range empty {
+		pi[idx] /= sum
+	}
+	return
+}
+
+// Move samples a move from the policy.
+func (pp *PolicyPlayer) Move(b Board) (mv *Move, err error) {
+	moves, err := ValidMoves(b, pp.pid)
+	if err != nil {
+		return nil, err
+	}
+	_, pi := pp.policy(gridOf(b))
+	r := rand.Float64()
+	for _, m := range moves {
+		r -= pi[loc(m.Row, m.Col)]
+		if r <= 0 {
+			return m, nil
+		}
+	}
+	return moves[len(moves)-1], nil
+}
+
+// Train applies one policy gradient step from the player's moves in games.
+// The return for each move is the final reward discounted by gamma for every
+// later move the player made.
+func (pp *PolicyPlayer) Train(games []*GamePlayed) {
+	pp.episodes += len(games)
+	var actor, critic *tensor.Sample[float64]
+	for i := range games {
+		reward := pp.rewards[2]
+		switch games[i].Outcome() {
+		case float64(pp.pid):
+			reward = pp.rewards[0]
+		case float64(other(pp.pid)):
+			reward = pp.rewards[1]
+		}
+
+		// player 1 goes first so positions 0,2,4,6,8 are theirs
+		start := 0
+		if pp.pid == 2 {
+			start = 1
+		}
+		positions := games[i].Positions()
+		if len(positions) <= start {
+			continue
+		}
+		ret := reward
+		for j := start + 2*((len(positions)-1-start)/2); j >= start; j -= 2 {
+			g, idx, _ := decode(positions[j])
+			X := g.perspective(pp.pid)
+			advantage := ret
+			if pp.critic != nil {
+				advantage -= pp.critic.Forward(X).Get(0, 0)
+				critic = appendSample(critic, X, []float64{ret})
+			}
+			logits, pi := pp.policy(g)
+			y := make([]float64, 9)
+			for k := range y {
+				y[k] = logits[k]
+			}
+			for _, k := range g.empty() {
+				grad := -pi[k]
+				if k == idx {
+					grad += 1
+				}
+				y[k] += pp.step * advantage * grad
+			}
+			actor = appendSample(actor, X, y)
+			ret *= pp.gamma
+		}
+	}
+	if actor != nil {
+		pp.actor.Iterate(actor)
+	}
+	if critic != nil {
+		pp.critic.Iterate(critic)
+	}
+}
+
+// appendSample adds the column X with target y to s, creating s if it is nil.
+func appendSample(s *tensor.Sample[float64], X *tensor.Tensor[float64], y []float64) *tensor.Sample[float64] {
+	next := tensor.NewSample[float64](X, tensor.New(tensor.WithShape[float64](len(y), 1), tensor.WithBacking(y)))
+	if s == nil {
+		return next
+	}
+	s.Append(next)
+	return s
+}
+
+func (pp *PolicyPlayer) Persist(path string) error {
+	fmt.Println("saving network to file", path)
+	var payload bytes.Buffer
+	pp.actor.Write(&payload)
+	baseline := 0.0
+	if pp.critic != nil {
+		pp.critic.Write(&payload)
+		baseline = 1
+	}
+	h := ModelHeader{
+		Type:     "policyplayer",
+		Pid:      pp.pid,
+		Hyper:    map[string]float64{"gamma": pp.gamma, "step": pp.step, "baseline": baseline},
+		Episodes: pp.episodes,
+	}
+	return saveModel(path, h, payload.Bytes())
+}
+
+// Display shows the probability the policy gives each empty cell.
+func (pp *PolicyPlayer) Display(b Board) {
+	_, pi := pp.policy(gridOf(b))
+	fmt.Println("         |     0     |     1     |     2     ")
+	fmt.Println("---------+-----------+-----------+-----------")
+	for i := 0; i < 3; i++ {
+		cells := make([]string, 3)
+		for j := 0; j < 3; j++ {
+			p, _ := b.Get(i, j)
+			cells[j] = convert(p)
+			if cells[j] == "" {
+				cells[j] = fmt.Sprintf("%9.5f", pi[loc(i, j)])
+			}
+		}
+		fmt.Printf("    %d    | %s | %s | %s\n", i, cells[0], cells[1], cells[2])
+		if i < 2 {
+			fmt.Println("---------+---------+---------+---------")
+		} else {
+			fmt.Println()
+		}
+	}
+}
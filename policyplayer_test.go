@@ -0,0 +1,53 @@
+package tictactoe
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPolicyMask(t *testing.T) {
+	pp := NewPolicyPlayer(2, 0.9, false)
+	g := gridOf(&BoardImp{data: [][]int{
+		{1, 2, 0},
+		{0, 1, 0},
+		{0, 0, 0},
+	}})
+	_, pi := pp.policy(g)
+	sum := 0.0
+	for i := range pi {
+		if g[i] != 0 && pi[i] != 0 {
+			t.Errorf("occupied cell %d has probability %.5f", i, pi[i])
+		}
+		sum += pi[i]
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Errorf("expected probabilities to sum to 1, got %.5f", sum)
+	}
+}
+
+func TestPolicyTrain(t *testing.T) {
+	win := winGame(t)
+	// the last position player 1 moved in, where its move won
+	g, idx, _ := decode(win.Positions()[len(win.Positions())-1])
+	for _, baseline := range []bool{false, true} {
+		pp := NewPolicyPlayer(1, 0.9, baseline)
+		_, before := pp.policy(g)
+		var value float64
+		if baseline {
+			value = pp.critic.Forward(g.perspective(1)).Get(0, 0)
+		}
+		for i := 0; i < 10; i++ {
+			pp.Train([]*GamePlayed{win})
+		}
+		if _, after := pp.policy(g); after[idx] <= before[idx] {
+			t.Errorf("baseline %v: expected the winning move to become more likely, %.5f then %.5f", baseline, before[idx], after[idx])
+		}
+		if baseline {
+			// the winning move returns the full reward for a win
+			after := pp.critic.Forward(g.perspective(1)).Get(0, 0)
+			if math.Abs(after-pp.rewards[0]) >= math.Abs(value-pp.rewards[0]) {
+				t.Errorf("expected the critic to move towards %.2f, %.5f then %.5f", pp.rewards[0], value, after)
+			}
+		}
+	}
+}
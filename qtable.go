@@ -0,0 +1,193 @@
+package tictactoe
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+)
+
+// QTablePlayer is a tabular Q-learning player. It keeps a value for every
+// state action pair it has seen in a map keyed by the board, which is
+// practical because tic tac toe only has a few thousand reachable positions.
+// It is the reference that the neural network players should converge to.
+type QTablePlayer struct {
+	pid     int
+	epsilon float64
+	// alpha is the learning rate for each backup
+	alpha float64
+	gamma float64
+	// rewards is a 3 element slice 0: win, 1: loss, 2: draw
+	rewards []float64
+	table   map[string]*[9]float64
+}
+
+// NewQTablePlayer returns a player with an empty table, call Load to play a
+// persisted one.
+func NewQTablePlayer(pid int, epsilon, alpha, gamma float64) *QTablePlayer {
+	qp := &QTablePlayer{
+		pid:     pid,
+		epsilon: epsilon,
+		alpha:   alpha,
+		gamma:   gamma,
+		rewards: []float64{1.0, -1.0, 0.0},
+		table:   make(map[string]*[9]float64),
+	}
+	return qp
+}
+
+// Load reads the table saved at path.
+func (qp *QTablePlayer) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	table := make(map[string]*[9]float64)
+	if err := json.Unmarshal(data, &table); err != nil {
+		return fmt.Errorf("reading q-table %s: %w", path, err)
+	}
+	qp.table = table
+	return nil
+}
+
+func (qp *QTablePlayer) Epsilon() float64 {
+	return qp.epsilon
+}
+
+func (qp *QTablePlayer) SetEpsilon(epsilon float64) {
+	qp.epsilon = epsilon
+}
+
+// stateKey encodes a board as a nine character string of player ids in grid
+// order, e.g. "100020000".
+func stateKey(g grid) string {
+	out := make([]byte, 9)
+	for i := range g {
+		out[i] = strconv.Itoa(g[i])[0]
+	}
+	return string(out)
+}
+
+// values returns the action values for g, adding a row of zeros the first
+// time a state is seen.
+func (qp *QTablePlayer) values(g grid) *[9]float64 {
+	k := stateKey(g)
+	q, ok := qp.table[k]
+	if !ok {
+		q = &[9]float64{}
+		qp.table[k] = q
+	}
+	return q
+}
+
+// best returns the highest action value over the empty cells of g, 0 if
+// there are none.
+func (qp *QTablePlayer) best(g grid) float64 {
+	empty := g.empty()
+	if len(empty) == 0 {
+		return 0
+	}
+	q := qp.values(g)
+	out := math.Inf(-1)
+	for _, idx := range empty {
+		out = math.Max(out, q[idx])
+	}
+	return out
+}
+
+// Move plays the move with the highest value in the table with probability
+// 1-epsilon and a random move otherwise. Ties go to the first move.
+func (qp *QTablePlayer) Move(b Board) (mv *Move, err error) {
+	moves, err := ValidMoves(b, qp.pid)
+	if err != nil {
+		return nil, err
+	}
+	if rand.Float64() < qp.epsilon {
+		return (&RandomPlayer{pid: qp.pid}).Move(b)
+	}
+	g := gridOf(b)
+	q := qp.values(g)
+	mv = moves[0]
+	for _, m := range moves[1:] {
+		if q[loc(m.Row, m.Col)] > q[loc(mv.Row, mv.Col)] {
+			mv = m
+		}
+	}
+	return
+}
+
+// Train applies one-step Q-learning backups for every move this player made
+// in games,
+//
+// Q(s_t, a_t) <- Q(s_t, a_t) + alpha*[r_t + gamma*max_a Q(s_{t+1}, a) - Q(s_t, a_t)]
+//
+// where s_{t+1} is the board the next time it is this player's turn. Only
+// the last move of a game earns a reward and has no successor. The moves of
+// each game are backed up from the end of the game to the start so the final
+// reward reaches the opening in a single pass.
+func (qp *QTablePlayer) Train(games []*GamePlayed) {
+	for i := range games {
+		reward := qp.rewards[2]
+		switch games[i].Outcome() {
+		case float64(qp.pid):
+			reward = qp.rewards[0]
+		case float64(other(qp.pid)):
+			reward = qp.rewards[1]
+		}
+
+		// player 1 goes first so positions 0,2,4,6,8 are theirs
+		start := 0
+		if qp.pid == 2 {
+			start = 1
+		}
+		positions := games[i].Positions()
+		if len(positions) <= start {
+			continue
+		}
+		var next *grid
+		for j := start + 2*((len(positions)-1-start)/2); j >= start; j -= 2 {
+			g, idx, _ := decode(positions[j])
+			target := reward
+			if next != nil {
+				target = qp.gamma * qp.best(*next)
+			}
+			q := qp.values(g)
+			q[idx] += qp.alpha * (target - q[idx])
+			next = &g
+		}
+	}
+}
+
+// Persist writes the table to path as JSON.
+func (qp *QTablePlayer) Persist(path string) error {
+	fmt.Println("saving q-table to file", path)
+	return WriteFileAtomic(path, func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(qp.table)
+	})
+}
+
+// Display shows the table value of each empty cell.
+func (qp *QTablePlayer) Display(b Board) {
+	q := qp.values(gridOf(b))
+	fmt.Println("         |     0     |     1     |     2     ")
+	fmt.Println("---------+-----------+-----------+-----------")
+	for i := 0; i < 3; i++ {
+		cells := make([]string, 3)
+		for j := 0; j < 3; j++ {
+			p, _ := b.Get(i, j)
+			cells[j] = convert(p)
+			if cells[j] == "" {
+				cells[j] = fmt.Sprintf("%9.5f", q[loc(i, j)])
+			}
+		}
+		fmt.Printf("    %d    | %s | %s | %s\n", i, cells[0], cells[1], cells[2])
+		if i < 2 {
+			fmt.Println("---------+---------+---------+---------")
+		} else {
+			fmt.Println()
+		}
+	}
+}
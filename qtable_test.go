@@ -0,0 +1,75 @@
+package tictactoe
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestQTableBackup(t *testing.T) {
+	b := NewBoard()
+	b.Reset()
+	// player 1 wins down the first column
+	for _, mv := range []*Move{{1, 0, 0}, {2, 0, 1}, {1, 1, 0}, {2, 1, 1}, {1, 2, 0}} {
+		if err := b.Move(mv); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	b.GameOver()
+	qp := NewQTablePlayer(1, 0, 0.5, 0.9)
+	qp.Train([]*GamePlayed{b.GamePlayed()})
+
+	positions := b.GamePlayed().Positions()
+	g, idx, _ := decode(positions[4])
+	if v := qp.values(g)[idx]; v != 0.5 {
+		t.Errorf("expected the winning move to be worth 0.5, got %.5f", v)
+	}
+	g, idx, _ = decode(positions[2])
+	if v := qp.values(g)[idx]; v != 0.5*0.9*0.5 {
+		t.Errorf("expected the move before the win to be worth %.5f, got %.5f", 0.5*0.9*0.5, v)
+	}
+
+	loser := NewQTablePlayer(2, 0, 0.5, 0.9)
+	loser.Train([]*GamePlayed{b.GamePlayed()})
+	g, idx, _ = decode(positions[3])
+	if v := loser.values(g)[idx]; v != -0.5 {
+		t.Errorf("expected the last losing move to be worth -0.5, got %.5f", v)
+	}
+}
+
+func TestQTableLearns(t *testing.T) {
+	qp := NewQTablePlayer(1, 0.2, 0.5, 0.9)
+	rp := NewRandomPlayer(2)
+	for i := 0; i < 20000; i++ {
+		b := NewBoard()
+		b.Reset()
+		players := []Player{qp, rp}
+		for turn := 0; b.GameOver() == 0; turn++ {
+			mv, _ := players[turn%2].Move(b)
+			b.Move(mv)
+		}
+		qp.Train([]*GamePlayed{b.GamePlayed()})
+	}
+	qp.SetEpsilon(0)
+	wins := 0
+	for i := 0; i < 200; i++ {
+		if playGame(t, qp, rp) == 1 {
+			wins++
+		}
+	}
+	// random play wins about 58% of the time as player 1
+	if wins < 150 {
+		t.Errorf("expected a trained table to beat a random player, won %d of 200", wins)
+	}
+
+	path := filepath.Join(t.TempDir(), "qtable.json")
+	if err := qp.Persist(path); err != nil {
+		t.Fatal(err.Error())
+	}
+	loaded := NewQTablePlayer(1, 0, 0.5, 0.9)
+	if err := loaded.Load(path); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(loaded.table) != len(qp.table) {
+		t.Errorf("expected %d states after loading, got %d", len(qp.table), len(loaded.table))
+	}
+}
@@ -0,0 +1,101 @@
+package tictactoe
+
+import (
+	"math"
+)
+
+// Rating is an estimate of an entrant's strength. Dev is the Glicko rating
+// deviation, 0 for Elo ratings which do not track their uncertainty.
+type Rating struct {
+	Value float64
+	Dev   float64
+}
+
+const (
+	// InitialRating is where every entrant starts.
+	InitialRating = 1500.0
+	// InitialDev is the Glicko deviation of an entrant that has not played.
+	InitialDev = 350.0
+)
+
+// expected is the score a player rated r expects against one rated opp, with
+// the rating difference scaled by g as in Glicko, 1 for Elo.
+func expected(r, opp, g float64) float64 {
+	return 1 / (1 + math.Pow(10, -g*(r-opp)/400))
+}
+
+// Elo rates the entrants by updating their ratings after every game with
+// factor k, in the order the games were played. Rounds interleave the
+// pairings so no entrant is rated on all its games against one opponent
+// before meeting the next.
+func Elo(t *Tournament, k float64) []Rating {
+	out := make([]Rating, len(t.Names))
+	for i := range out {
+		out[i].Value = InitialRating
+	}
+	for _, round := range t.Rounds {
+		for _, r := range round {
+			ex := expected(out[r.X].Value, out[r.O].Value, 1)
+			delta := k * (r.Score(r.X) - ex)
+			out[r.X].Value += delta
+			out[r.O].Value -= delta
+		}
+	}
+	return out
+}
+
+// glickoQ is ln(10)/400.
+var glickoQ = math.Ln10 / 400
+
+// glickoG reduces the weight of a game against an opponent whose rating is
+// uncertain.
+func glickoG(dev float64) float64 {
+	return 1 / math.Sqrt(1+3*glickoQ*glickoQ*dev*dev/(math.Pi*math.Pi))
+}
+
+// Glicko rates the entrants with Glicko-1, treating every round as a rating
+// period. c is how much the deviation grows between periods, the deviation
+// never grows beyond InitialDev.
+func Glicko(t *Tournament, c float64) []Rating {
+	out := make([]Rating, len(t.Names))
+	for i := range out {
+		out[i] = Rating{Value: InitialRating, Dev: InitialDev}
+	}
+	for n, round := range t.Rounds {
+		if n > 0 {
+			for i := range out {
+				out[i].Dev = math.Min(math.Sqrt(out[i].Dev*out[i].Dev+c*c), InitialDev)
+			}
+		}
+		out = glickoPeriod(out, round)
+	}
+	return out
+}
+
+// glickoPeriod returns the ratings after the games of one rating period,
+// every entrant is updated from the ratings at the start of the period.
+func glickoPeriod(start []Rating, games []Result) []Rating {
+	out := append([]Rating{}, start...)
+	for i := range out {
+		d2inv, sum := 0.0, 0.0
+		for _, r := range games {
+			opp := r.O
+			if r.O == i {
+				opp = r.X
+			} else if r.X != i {
+				continue
+			}
+			g := glickoG(start[opp].Dev)
+			e := expected(start[i].Value, start[opp].Value, g)
+			d2inv += glickoQ * glickoQ * g * g * e * (1 - e)
+			sum += g * (r.Score(i) - e)
+		}
+		if d2inv == 0 {
+			continue
+		}
+		v := 1/(start[i].Dev*start[i].Dev) + d2inv
+		out[i].Value = start[i].Value + glickoQ/v*sum
+		out[i].Dev = math.Sqrt(1 / v)
+	}
+	return out
+}
@@ -0,0 +1,44 @@
+package tictactoe
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGlickoPeriod(t *testing.T) {
+	// the example from Glickman's description of the Glicko system, a
+	// player rated 1500 beats a 1400 and loses to a 1550 and a 1700
+	start := []Rating{{1500, 200}, {1400, 30}, {1550, 100}, {1700, 300}}
+	games := []Result{{X: 0, O: 1, Winner: 0}, {X: 0, O: 2, Winner: 2}, {X: 3, O: 0, Winner: 3}}
+	out := glickoPeriod(start, games)
+	if math.Abs(out[0].Value-1464.1) > 0.1 || math.Abs(out[0].Dev-151.4) > 0.1 {
+		t.Errorf("expected 1464.1 and 151.4, got %.1f and %.1f", out[0].Value, out[0].Dev)
+	}
+}
+
+func TestRatingsOrder(t *testing.T) {
+	// entrant 0 beats everyone, 1 beats 2, and 2 ties itself out of nothing
+	tour := &Tournament{Names: []string{"strong", "middle", "weak"}}
+	for g := 0; g < 10; g++ {
+		tour.Rounds = append(tour.Rounds, []Result{
+			{X: 0, O: 1, Winner: 0}, {X: 1, O: 0, Winner: -1},
+			{X: 0, O: 2, Winner: 0}, {X: 2, O: 0, Winner: 0},
+			{X: 1, O: 2, Winner: 1}, {X: 2, O: 1, Winner: 1},
+		})
+	}
+	for name, ratings := range map[string][]Rating{"elo": Elo(tour, 16), "glicko": Glicko(tour, 30)} {
+		if !(ratings[0].Value > ratings[1].Value && ratings[1].Value > ratings[2].Value) {
+			t.Errorf("%s: expected the ratings in order, got %v", name, ratings)
+		}
+	}
+	sum := 0.0
+	for _, r := range Elo(tour, 16) {
+		sum += r.Value
+	}
+	if math.Abs(sum-3*InitialRating) > 1e-6 {
+		t.Errorf("expected elo to keep the total rating, got %f", sum)
+	}
+	if g := Glicko(tour, 30); g[0].Dev >= InitialDev {
+		t.Errorf("expected the deviation to shrink, got %f", g[0].Dev)
+	}
+}
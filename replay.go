@@ -0,0 +1,255 @@
+package tictactoe
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"bigfunbrewing.com/tensor"
+)
+
+// transition is a single move made by a player. state and action are the
+// board and the cell the player chose, reward is what the move earned, next
+// is the board the next time it was the player's turn and done is set when
+// the move ended the game, in which case next is meaningless.
+type transition struct {
+	state  grid
+	action int
+	pid    int
+	reward float64
+	next   grid
+	done   bool
+}
+
+// position rebuilds the network input for the transition's state and action.
+func (t *transition) position() Position {
+	return MakePosition(t.state.board(), t.state.move(t.pid, t.action))
+}
+
+// ReplayBuffer is a bounded ring buffer of transitions for experience
+// replay. Once it is full the oldest transitions are overwritten. Sampling is
+// uniform unless the buffer is prioritized, in which case transitions are
+// drawn in proportion to (|TD error| + 0.01)^alpha so the moves the network
+// predicts worst are replayed most often. New transitions get the highest
+// priority seen so far so that each is replayed at least once early on.
+//
+// Prioritized sampling replays some transitions more often than they
+// occurred, which biases the updates. Each update is weighted by the
+// importance sampling weight (N*P(i))^-beta, scaled so the largest weight in
+// the minibatch is 1. beta 1 removes the bias entirely, 0 ignores it.
+type ReplayBuffer struct {
+	items       []transition
+	priorities  []float64
+	next        int
+	size        int
+	prioritized bool
+	alpha       float64
+	beta        float64
+	max         float64
+}
+
+func NewReplayBuffer(capacity int, prioritized bool, alpha, beta float64) *ReplayBuffer {
+	return &ReplayBuffer{
+		items:       make([]transition, capacity),
+		priorities:  make([]float64, capacity),
+		prioritized: prioritized,
+		alpha:       alpha,
+		beta:        beta,
+		max:         1.0,
+	}
+}
+
+// Len returns the number of transitions held by the buffer.
+func (rb *ReplayBuffer) Len() int {
+	return rb.size
+}
+
+func (rb *ReplayBuffer) add(t transition) {
+	rb.items[rb.next] = t
+	rb.priorities[rb.next] = rb.max
+	rb.next = (rb.next + 1) % len(rb.items)
+	if rb.size < len(rb.items) {
+		rb.size++
+	}
+}
+
+// AddGames extracts pid's transitions from games and adds them along with
+// their 90, 180 and 270 degree rotations. rewards is a 3 element slice 0:
+// win, 1: loss, 2: draw, as in makeSamples.
+func (rb *ReplayBuffer) AddGames(games []*GamePlayed, pid int, rewards []float64) {
+	for i := range games {
+		positions := games[i].Positions()
+		// player 1 goes first so positions 0,2,4,6,8 are theirs
+		start := 0
+		if pid == 2 {
+			start = 1
+		}
+		moves := (len(positions) - start + 1) / 2
+		if moves <= 0 {
+			continue
+		}
+		reward := finalReward(games[i], pid, rewards, moves)
+		for j := start; j < len(positions); j += 2 {
+			var t transition
+			t.state, t.action, t.pid = decode(positions[j])
+			if j+2 < len(positions) {
+				t.next, _, _ = decode(positions[j+2])
+			} else {
+				t.done = true
+				t.reward = reward
+			}
+			for k := 0; k < 4; k++ {
+				rt := t
+				rt.state = t.state.transform(k)
+				rt.action = symmetries[k][t.action]
+				rt.next = t.next.transform(k)
+				rb.add(rt)
+			}
+		}
+	}
+}
+
+// sample draws n transitions and returns their offsets in the buffer.
+func (rb *ReplayBuffer) sample(n int) []int {
+	out := make([]int, n)
+	if !rb.prioritized {
+		for i := range out {
+			out[i] = rand.Intn(rb.size)
+		}
+		return out
+	}
+	total := 0.0
+	for i := 0; i < rb.size; i++ {
+		total += rb.priorities[i]
+	}
+	draws := make([]float64, n)
+	for i := range draws {
+		draws[i] = rand.Float64() * total
+	}
+	sort.Float64s(draws)
+	sum := 0.0
+	idx := 0
+	for i := range draws {
+		for idx < rb.size-1 && sum+rb.priorities[idx] < draws[i] {
+			sum += rb.priorities[idx]
+			idx++
+		}
+		out[i] = idx
+	}
+	return out
+}
+
+// weights returns the importance sampling weight of each sampled
+// transition, all 1 unless the buffer is prioritized.
+func (rb *ReplayBuffer) weights(idx []int) []float64 {
+	out := make([]float64, len(idx))
+	for i := range out {
+		out[i] = 1
+	}
+	if !rb.prioritized || rb.beta == 0 {
+		return out
+	}
+	total := 0.0
+	for i := 0; i < rb.size; i++ {
+		total += rb.priorities[i]
+	}
+	max := 0.0
+	for i := range idx {
+		p := rb.priorities[idx[i]] / total
+		out[i] = math.Pow(float64(rb.size)*p, -rb.beta)
+		max = math.Max(max, out[i])
+	}
+	for i := range out {
+		out[i] /= max
+	}
+	return out
+}
+
+// update sets new priorities from the TD errors of the sampled transitions.
+func (rb *ReplayBuffer) update(idx []int, errs []float64) {
+	for i := range idx {
+		p := math.Pow(math.Abs(errs[i])+0.01, rb.alpha)
+		rb.priorities[idx[i]] = p
+		rb.max = math.Max(rb.max, p)
+	}
+}
+
+// SetReplay makes the player train from buf instead of only the latest
+// games. Each call to Train adds the new games to buf and fits the network to
+// a minibatch of batch transitions with the DQN target
+//
+// gamma*max_a Q'(s', a)
+//
+// or gamma*r for the move that ended the game, where Q' is a frozen copy of
+// the network that is synced with the live network every syncEvery calls to
+// Train. The final reward is discounted as by the other target modes so the
+// values are on the same scale. Replay always uses this target,
+// the mode set with SetTarget only applies when training without replay.
+// batch has to be positive.
+func (mp *MlannPlayer) SetReplay(buf *ReplayBuffer, batch, syncEvery int) error {
+	if batch <= 0 {
+		return fmt.Errorf("replay batch size has to be positive, got %d", batch)
+	}
+	mp.replay = buf
+	mp.batch = batch
+	mp.syncEvery = syncEvery
+	mp.syncTarget()
+	return nil
+}
+
+// syncTarget copies the live network into the frozen target network.
+func (mp *MlannPlayer) syncTarget() {
+	var buf bytes.Buffer
+	mp.net.Write(&buf)
+	mp.frozen = newMlannNetwork(mp.cfg)
+	mp.frozen.Read(&buf)
+}
+
+// replayTarget is the DQN target of t.
+func (mp *MlannPlayer) replayTarget(t *transition) float64 {
+	if t.done {
+		return mp.gamma * t.reward
+	}
+	b := t.next.board()
+	moves, err := ValidMoves(b, mp.pid)
+	if err != nil {
+		return 0
+	}
+	best := math.Inf(-1)
+	for _, mv := range moves {
+		best = math.Max(best, mp.frozen.Forward(MakePosition(b, mv)).Get(0, 0))
+	}
+	return mp.gamma * best
+}
+
+// trainReplay is Train when an experience replay buffer is configured.
+func (mp *MlannPlayer) trainReplay(games []*GamePlayed) {
+	mp.replay.AddGames(games, mp.pid, []float64{10.0, -10.0, 0.1})
+	if mp.replay.Len() < mp.batch {
+		return
+	}
+	idx := mp.replay.sample(mp.batch)
+	weights := mp.replay.weights(idx)
+	errs := make([]float64, len(idx))
+	var sample *tensor.Sample[float64]
+	for i := range idx {
+		t := &mp.replay.items[idx[i]]
+		y := mp.replayTarget(t)
+		X := t.position()
+		q := mp.net.Forward(X).Get(0, 0)
+		errs[i] = y - q
+		// moving the target closer to the prediction scales the squared
+		// error gradient by the importance sampling weight
+		sample = appendSample(sample, X, []float64{q + weights[i]*errs[i]})
+	}
+	mp.replay.update(idx, errs)
+	mp.net.Iterate(sample)
+	mp.loss = sampleLoss(mp.net, sample)
+
+	mp.trains++
+	if mp.syncEvery > 0 && mp.trains%mp.syncEvery == 0 {
+		mp.syncTarget()
+	}
+}
@@ -0,0 +1,122 @@
+package tictactoe
+
+import (
+	"math"
+	"testing"
+)
+
+// winGame returns a game where player 1 wins down the first column.
+func winGame(t *testing.T) *GamePlayed {
+	b := NewBoard()
+	b.Reset()
+	for _, mv := range []*Move{{1, 0, 0}, {2, 0, 1}, {1, 1, 0}, {2, 1, 1}, {1, 2, 0}} {
+		if err := b.Move(mv); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	b.GameOver()
+	return b.GamePlayed()
+}
+
+func TestReplayAddGames(t *testing.T) {
+	rb := NewReplayBuffer(100, false, 0.6, 0.4)
+	rb.AddGames([]*GamePlayed{winGame(t)}, 1, []float64{10.0, -10.0, 0.1})
+	// three moves, each under four rotations
+	if rb.Len() != 12 {
+		t.Fatalf("expected 12 transitions, got %d", rb.Len())
+	}
+	done := 0
+	for i := 0; i < rb.Len(); i++ {
+		if rb.items[i].done {
+			done++
+			if rb.items[i].reward != 10.0/3 {
+				t.Errorf("expected the winning move to earn %.5f, got %.5f", 10.0/3, rb.items[i].reward)
+			}
+		}
+	}
+	if done != 4 {
+		t.Errorf("expected 4 terminal transitions, got %d", done)
+	}
+	rb.AddGames([]*GamePlayed{winGame(t)}, 2, []float64{10.0, -10.0, 0.1})
+	if rb.Len() != 20 {
+		t.Errorf("expected 20 transitions, got %d", rb.Len())
+	}
+}
+
+func TestReplayRing(t *testing.T) {
+	rb := NewReplayBuffer(10, false, 0.6, 0.4)
+	for i := 0; i < 3; i++ {
+		rb.AddGames([]*GamePlayed{winGame(t)}, 1, []float64{10.0, -10.0, 0.1})
+	}
+	if rb.Len() != 10 {
+		t.Errorf("expected the buffer to stop growing at 10, got %d", rb.Len())
+	}
+	if rb.next != 36%10 {
+		t.Errorf("expected the write offset to wrap to %d, got %d", 36%10, rb.next)
+	}
+}
+
+func TestReplayPrioritized(t *testing.T) {
+	rb := NewReplayBuffer(100, true, 1.0, 1.0)
+	rb.AddGames([]*GamePlayed{winGame(t)}, 1, []float64{10.0, -10.0, 0.1})
+	idx := make([]int, rb.Len())
+	errs := make([]float64, rb.Len())
+	for i := range idx {
+		idx[i] = i
+	}
+	errs[5] = 100
+	rb.update(idx, errs)
+	hits := 0
+	for _, i := range rb.sample(1000) {
+		if i == 5 {
+			hits++
+		}
+	}
+	if hits < 900 {
+		t.Errorf("expected the high error transition to dominate sampling, drawn %d of 1000", hits)
+	}
+	w := rb.weights([]int{5, 6})
+	if w[0] >= w[1] || w[1] != 1 {
+		t.Errorf("expected the often replayed transition to weigh less, got %v", w)
+	}
+}
+
+func TestMlannReplayTrain(t *testing.T) {
+	mp := NewMlannPlayer(1, 0.0, 0.9)
+	if err := mp.SetReplay(NewReplayBuffer(1000, true, 0.6, 0.4), 8, 2); err != nil {
+		t.Fatal(err.Error())
+	}
+	for i := 0; i < 4; i++ {
+		mp.Train([]*GamePlayed{winGame(t)})
+	}
+	if mp.trains != 4 {
+		t.Errorf("expected 4 replay updates, got %d", mp.trains)
+	}
+}
+
+func TestReplayTarget(t *testing.T) {
+	mp := NewMlannPlayer(1, 0.0, 0.9)
+	rb := NewReplayBuffer(100, false, 0.6, 0.4)
+	if err := mp.SetReplay(rb, 8, 2); err != nil {
+		t.Fatal(err.Error())
+	}
+	rb.AddGames([]*GamePlayed{winGame(t)}, 1, []float64{10.0, -10.0, 0.1})
+	// the winning move is discounted once like the other target modes
+	want := 0.9 * 10.0 / 3
+	for i := 0; i < rb.Len(); i++ {
+		if rb.items[i].done {
+			if y := mp.replayTarget(&rb.items[i]); math.Abs(y-want) > 1e-9 {
+				t.Errorf("expected the winning move to target %.5f, got %.5f", want, y)
+			}
+		}
+	}
+}
+
+func TestMlannReplayBatch(t *testing.T) {
+	mp := NewMlannPlayer(1, 0.0, 0.9)
+	for _, batch := range []int{0, -1} {
+		if err := mp.SetReplay(NewReplayBuffer(10, false, 0.6, 0.4), batch, 2); err == nil {
+			t.Errorf("expected an error for batch size %d", batch)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package tictactoe
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Schedule is a value, such as an exploration or learning rate, that changes
+// over the episodes of a training run.
+type Schedule interface {
+	// At returns the value for episode t, counting from 0.
+	At(t int) float64
+}
+
+// Constant never changes.
+type Constant struct {
+	Value float64
+}
+
+func (c Constant) At(t int) float64 {
+	return c.Value
+}
+
+// Linear moves in a straight line from From to To over Steps episodes and
+// stays at To afterwards.
+type Linear struct {
+	From  float64
+	To    float64
+	Steps int
+}
+
+func (l Linear) At(t int) float64 {
+	return l.From + (l.To-l.From)*progress(t, l.Steps)
+}
+
+// Exponential multiplies From by Decay every episode, never going below Min.
+type Exponential struct {
+	From  float64
+	Decay float64
+	Min   float64
+}
+
+func (e Exponential) At(t int) float64 {
+	return math.Max(e.Min, e.From*math.Pow(e.Decay, float64(t)))
+}
+
+// Step multiplies From by Factor once every Every episodes.
+type Step struct {
+	From   float64
+	Factor float64
+	Every  int
+}
+
+func (s Step) At(t int) float64 {
+	if s.Every <= 0 {
+		return s.From
+	}
+	return s.From * math.Pow(s.Factor, float64(t/s.Every))
+}
+
+// Cosine follows half a cosine wave from From to To over Steps episodes, so
+// it changes slowly at the start and end and quickly in the middle, and stays
+// at To afterwards.
+type Cosine struct {
+	From  float64
+	To    float64
+	Steps int
+}
+
+func (c Cosine) At(t int) float64 {
+	return c.To + (c.From-c.To)*(1+math.Cos(math.Pi*progress(t, c.Steps)))/2
+}
+
+// progress is the fraction of steps that episode t has completed.
+func progress(t, steps int) float64 {
+	if steps <= 0 || t >= steps {
+		return 1
+	}
+	return float64(t) / float64(steps)
+}
+
+// ParseSchedule converts the command line form of a schedule into a
+// Schedule. The form is the name of the schedule followed by its parameters,
+//
+//	constant:value
+//	linear:from,to,steps
+//	exp:from,decay[,min]
+//	step:from,factor,every
+//	cosine:from,to,steps
+//
+// and a bare number is a constant.
+func ParseSchedule(s string) (Schedule, error) {
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return Constant{Value: v}, nil
+	}
+	name, params, _ := strings.Cut(s, ":")
+	args := make([]float64, 0)
+	if params != "" {
+		for _, p := range strings.Split(params, ",") {
+			v, err := strconv.ParseFloat(p, 64)
+			if err != nil {
+				return nil, fmt.Errorf("bad parameter %q in schedule %q", p, s)
+			}
+			args = append(args, v)
+		}
+	}
+	want := func(n ...int) error {
+		for _, k := range n {
+			if len(args) == k {
+				return nil
+			}
+		}
+		return fmt.Errorf("schedule %q expects %v parameters, got %d", s, n, len(args))
+	}
+	switch name {
+	case "constant":
+		if err := want(1); err != nil {
+			return nil, err
+		}
+		return Constant{Value: args[0]}, nil
+	case "linear":
+		if err := want(3); err != nil {
+			return nil, err
+		}
+		return Linear{From: args[0], To: args[1], Steps: int(args[2])}, nil
+	case "exp":
+		if err := want(2, 3); err != nil {
+			return nil, err
+		}
+		e := Exponential{From: args[0], Decay: args[1]}
+		if len(args) == 3 {
+			e.Min = args[2]
+		}
+		return e, nil
+	case "step":
+		if err := want(3); err != nil {
+			return nil, err
+		}
+		return Step{From: args[0], Factor: args[1], Every: int(args[2])}, nil
+	case "cosine":
+		if err := want(3); err != nil {
+			return nil, err
+		}
+		return Cosine{From: args[0], To: args[1], Steps: int(args[2])}, nil
+	}
+	return nil, fmt.Errorf("unknown schedule %q", s)
+}
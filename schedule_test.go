@@ -0,0 +1,62 @@
+package tictactoe
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSchedules(t *testing.T) {
+	type test struct {
+		s   Schedule
+		t   int
+		out float64
+	}
+	tests := []test{
+		{s: Constant{Value: 0.1}, t: 500, out: 0.1},
+		{s: Linear{From: 0.1, To: 0.0, Steps: 100}, t: 0, out: 0.1},
+		{s: Linear{From: 0.1, To: 0.0, Steps: 100}, t: 50, out: 0.05},
+		{s: Linear{From: 0.1, To: 0.0, Steps: 100}, t: 200, out: 0.0},
+		{s: Exponential{From: 1, Decay: 0.5}, t: 3, out: 0.125},
+		{s: Exponential{From: 1, Decay: 0.5, Min: 0.2}, t: 3, out: 0.2},
+		{s: Step{From: 1, Factor: 0.1, Every: 10}, t: 9, out: 1},
+		{s: Step{From: 1, Factor: 0.1, Every: 10}, t: 25, out: 0.01},
+		{s: Cosine{From: 1, To: 0, Steps: 100}, t: 0, out: 1},
+		{s: Cosine{From: 1, To: 0, Steps: 100}, t: 50, out: 0.5},
+		{s: Cosine{From: 1, To: 0, Steps: 100}, t: 100, out: 0},
+	}
+	for i := range tests {
+		if v := tests[i].s.At(tests[i].t); math.Abs(v-tests[i].out) > 1e-9 {
+			t.Errorf("test %d: expected %f, got %f", i, tests[i].out, v)
+		}
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	type test struct {
+		in  string
+		out Schedule
+		err bool
+	}
+	tests := []test{
+		{in: "0.1", out: Constant{Value: 0.1}},
+		{in: "constant:0.2", out: Constant{Value: 0.2}},
+		{in: "linear:0.1,0.01,10000", out: Linear{From: 0.1, To: 0.01, Steps: 10000}},
+		{in: "exp:0.1,0.99", out: Exponential{From: 0.1, Decay: 0.99}},
+		{in: "exp:0.1,0.99,0.01", out: Exponential{From: 0.1, Decay: 0.99, Min: 0.01}},
+		{in: "step:0.1,0.5,2000", out: Step{From: 0.1, Factor: 0.5, Every: 2000}},
+		{in: "cosine:0.1,0,5000", out: Cosine{From: 0.1, To: 0, Steps: 5000}},
+		{in: "linear:0.1,0.01", err: true},
+		{in: "linear:0.1,x,100", err: true},
+		{in: "sawtooth:1,2", err: true},
+	}
+	for i := range tests {
+		s, err := ParseSchedule(tests[i].in)
+		if (err != nil) != tests[i].err {
+			t.Errorf("test %d: unexpected error %v", i, err)
+			continue
+		}
+		if err == nil && s != tests[i].out {
+			t.Errorf("test %d: expected %v, got %v", i, tests[i].out, s)
+		}
+	}
+}
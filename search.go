@@ -0,0 +1,182 @@
+package tictactoe
+
+import (
+	"sync"
+)
+
+// symmetries holds the eight rotations and reflections of the board as
+// permutations of grid offsets. symmetries[k][i] is the offset that cell i
+// moves to under symmetry k.
+var symmetries = func() (out [8][9]int) {
+	for k := 0; k < 8; k++ {
+		for r := 0; r < 3; r++ {
+			for c := 0; c < 3; c++ {
+				rr, cc := r, c
+				if k >= 4 {
+					// reflect across the vertical axis
+					cc = 2 - cc
+				}
+				for n := 0; n < k%4; n++ {
+					// rotate 90 degrees
+					rr, cc = cc, 2-rr
+				}
+				out[k][loc(r, c)] = loc(rr, cc)
+			}
+		}
+	}
+	return
+}()
+
+// key encodes g as a base 3 number.
+func (g *grid) key() int {
+	k := 0
+	for i := len(g) - 1; i >= 0; i-- {
+		k = k*3 + g[i]
+	}
+	return k
+}
+
+// canonical returns the smallest key of g over all of its rotations and
+// reflections, so every board in a symmetry class shares the same key.
+func (g *grid) canonical() int {
+	best := -1
+	for k := range symmetries {
+		t := g.transform(k)
+		if v := t.key(); best < 0 || v < best {
+			best = v
+		}
+	}
+	return best
+}
+
+type bound int
+
+const (
+	exact bound = iota
+	lower
+	upper
+)
+
+type entry struct {
+	value int
+	bound bound
+}
+
+// Searcher is an alpha-beta game tree search that remembers every position it
+// has solved in a transposition table. Positions are keyed by their canonical
+// form so a board is never searched twice under a different rotation or
+// reflection. A Searcher is safe for concurrent use.
+//
+// Values are always from the point of view of the player making the move or
+// about to move. A win is worth 1 plus the number of empty cells left when
+// the game ends, a loss is the negative of that and a tie is 0. So the sign of
+// a value gives the outcome under perfect play, and among wins a larger value
+// is a faster win.
+type Searcher struct {
+	mu    sync.Mutex
+	table map[int]entry
+}
+
+func NewSearcher() *Searcher {
+	return &Searcher{table: make(map[int]entry)}
+}
+
+// Value returns the value of b for the player whose turn it is.
+func (s *Searcher) Value(b Board) int {
+	g := gridOf(b)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.solve(g)
+}
+
+// MoveValues returns each valid move for pid along with its value for pid.
+func (s *Searcher) MoveValues(b Board, pid int) ([]*Move, []int, error) {
+	moves, err := ValidMoves(b, pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	g := gridOf(b)
+	values := make([]int, len(moves))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range moves {
+		values[i] = s.moveValue(g, moves[i])
+	}
+	return moves, values, nil
+}
+
+// Size returns the number of positions in the transposition table.
+func (s *Searcher) Size() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.table)
+}
+
+// moveValue returns the value of playing mv on g for the player making it.
+func (s *Searcher) moveValue(g grid, mv *Move) int {
+	g[loc(mv.Row, mv.Col)] = mv.Pid
+	return -s.solve(g)
+}
+
+// solve returns the exact value of g for the player to move.
+func (s *Searcher) solve(g grid) int {
+	return s.negamax(g, g.toMove(), -10, 10)
+}
+
+// negamax is a fail-soft alpha-beta search. Entries in the table record
+// whether the stored value is exact or only a bound left by a cutoff.
+func (s *Searcher) negamax(g grid, toMove, alpha, beta int) int {
+	empty := g.empty()
+	switch g.winner() {
+	case -1:
+		return 0
+	case 1, 2:
+		// only the player who just moved can have won
+		return -(1 + len(empty))
+	}
+
+	start := alpha
+	key := g.canonical()
+	if e, ok := s.table[key]; ok {
+		switch e.bound {
+		case exact:
+			return e.value
+		case lower:
+			if e.value > alpha {
+				alpha = e.value
+			}
+		case upper:
+			if e.value < beta {
+				beta = e.value
+			}
+		}
+		if alpha >= beta {
+			return e.value
+		}
+	}
+
+	best := -10
+	for _, idx := range empty {
+		g[idx] = toMove
+		v := -s.negamax(g, other(toMove), -beta, -alpha)
+		g[idx] = 0
+		if v > best {
+			best = v
+		}
+		if v > alpha {
+			alpha = v
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+
+	e := entry{value: best, bound: exact}
+	if best <= start {
+		e.bound = upper
+	} else if best >= beta {
+		e.bound = lower
+	}
+	s.table[key] = e
+	return best
+}
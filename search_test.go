@@ -0,0 +1,84 @@
+package tictactoe
+
+import (
+	"testing"
+)
+
+// reference is a plain negamax with no pruning or memory that the Searcher
+// is checked against.
+func reference(g grid, toMove int) int {
+	empty := g.empty()
+	switch g.winner() {
+	case -1:
+		return 0
+	case 1, 2:
+		return -(1 + len(empty))
+	}
+	best := -10
+	for _, idx := range empty {
+		g[idx] = toMove
+		if v := -reference(g, other(toMove)); v > best {
+			best = v
+		}
+		g[idx] = 0
+	}
+	return best
+}
+
+func TestSearcherMatchesReference(t *testing.T) {
+	s := NewSearcher()
+	count := 0
+	reachable(grid{}, make(map[grid]bool), func(g grid) {
+		count++
+		want := reference(g, g.toMove())
+		if got := s.solve(g); got != want {
+			t.Errorf("%v: expected %d, got %d", g, want, got)
+		}
+	})
+	if count != 4520 {
+		t.Errorf("expected 4520 reachable non-terminal positions, found %d", count)
+	}
+	if s.Size() >= count {
+		t.Errorf("transposition table did not merge symmetric positions, %d entries", s.Size())
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	g := grid{}
+	g[loc(0, 0)] = 1
+	g[loc(0, 1)] = 2
+	for k := range symmetries {
+		sym := g.transform(k)
+		if sym.canonical() != g.canonical() {
+			t.Errorf("symmetry %d changed the canonical key", k)
+		}
+	}
+	h := grid{}
+	h[loc(0, 0)] = 1
+	h[loc(1, 1)] = 2
+	if h.canonical() == g.canonical() {
+		t.Errorf("different positions share a canonical key")
+	}
+}
+
+func TestSearcherValue(t *testing.T) {
+	b := NewBoard()
+	b.Reset()
+	s := NewSearcher()
+	if v := s.Value(b); v != 0 {
+		t.Errorf("expected the empty board to be a tie, got %d", v)
+	}
+	moves, values, err := s.MoveValues(&BoardImp{data: [][]int{
+		{1, 1, 0},
+		{2, 2, 0},
+		{0, 0, 0},
+	}}, 1)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	for i := range moves {
+		if moves[i].Row == 0 && moves[i].Col == 2 && values[i] != 5 {
+			t.Errorf("expected the immediate win to be worth 5, got %d", values[i])
+		}
+	}
+}
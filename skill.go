@@ -0,0 +1,105 @@
+package tictactoe
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+)
+
+// Named skill levels accepted by ParseSkill.
+const (
+	SkillEasy    = 0.3
+	SkillMedium  = 0.6
+	SkillHard    = 0.85
+	SkillPerfect = 1.0
+)
+
+// ParseSkill converts the command line name of a skill level into the
+// probability of playing the strong move. One of {easy, medium, hard,
+// perfect} or a number in [0,1].
+func ParseSkill(s string) (float64, error) {
+	switch s {
+	case "easy":
+		return SkillEasy, nil
+	case "medium":
+		return SkillMedium, nil
+	case "hard":
+		return SkillHard, nil
+	case "perfect", "":
+		return SkillPerfect, nil
+	}
+	skill, err := strconv.ParseFloat(s, 64)
+	if err != nil || skill < 0 || skill > 1 {
+		return 0, fmt.Errorf("unknown skill %q, expected easy, medium, hard, perfect or a number in [0,1]", s)
+	}
+	return skill, nil
+}
+
+// SkillPlayer makes a strong player beatable. With probability skill it
+// plays the move the strong player picks, otherwise it deliberately plays a
+// move that a full game tree search rates worse than the best one, or a
+// random move if every move is equally good. A skill of 0 blunders whenever
+// it can and a skill of 1 is the strong player itself.
+type SkillPlayer struct {
+	pid    int
+	skill  float64
+	strong Player
+	search *Searcher
+}
+
+// NewSkillPlayer wraps strong, nil uses a MinimaxPlayer so the strong move is
+// an optimal one.
+func NewSkillPlayer(pid int, skill float64, strong Player) *SkillPlayer {
+	if strong == nil {
+		strong = NewMinimaxPlayer(pid, TieRandom)
+	}
+	return &SkillPlayer{pid: pid, skill: skill, strong: strong, search: NewSearcher()}
+}
+
+func (sp *SkillPlayer) Move(b Board) (mv *Move, err error) {
+	if rand.Float64() < sp.skill {
+		return sp.strong.Move(b)
+	}
+	return sp.weak(b)
+}
+
+// weak returns a random move among those worse than the best move on b.
+func (sp *SkillPlayer) weak(b Board) (*Move, error) {
+	moves, values, err := sp.search.MoveValues(b, sp.pid)
+	if err != nil {
+		return nil, err
+	}
+	best := sign(values[0])
+	for i := range values {
+		if sign(values[i]) > best {
+			best = sign(values[i])
+		}
+	}
+	worse := make([]*Move, 0, len(moves))
+	for i := range moves {
+		if sign(values[i]) < best {
+			worse = append(worse, moves[i])
+		}
+	}
+	if len(worse) == 0 {
+		worse = moves
+	}
+	return worse[rand.Intn(len(worse))], nil
+}
+
+func (sp *SkillPlayer) Train(games []*GamePlayed) {
+	sp.strong.Train(games)
+}
+
+func (sp *SkillPlayer) Persist(path string) error {
+	return sp.strong.Persist(path)
+}
+
+func (sp *SkillPlayer) Load(path string) error {
+	return sp.strong.Load(path)
+}
+
+func (sp *SkillPlayer) Display(b Board) {
+	fmt.Printf("skill player %d: skill %.2f\n", sp.pid, sp.skill)
+	sp.strong.Display(b)
+}
@@ -0,0 +1,76 @@
+package tictactoe
+
+import (
+	"testing"
+)
+
+func TestParseSkill(t *testing.T) {
+	type test struct {
+		in  string
+		out float64
+		err bool
+	}
+	tests := []test{
+		{in: "easy", out: SkillEasy},
+		{in: "medium", out: SkillMedium},
+		{in: "hard", out: SkillHard},
+		{in: "perfect", out: SkillPerfect},
+		{in: "0.5", out: 0.5},
+		{in: "1.5", err: true},
+		{in: "expert", err: true},
+	}
+	for i := range tests {
+		out, err := ParseSkill(tests[i].in)
+		if (err != nil) != tests[i].err {
+			t.Errorf("test %d: unexpected error %v", i, err)
+		}
+		if err == nil && out != tests[i].out {
+			t.Errorf("test %d: expected %f, got %f", i, tests[i].out, out)
+		}
+	}
+}
+
+func TestSkillPlayerBlunders(t *testing.T) {
+	// player 1 wins at (0,2), any other move lets player 2 win or tie
+	b := &BoardImp{data: [][]int{
+		{1, 1, 0},
+		{2, 2, 0},
+		{0, 0, 0},
+	}}
+	win := Move{Pid: 1, Row: 0, Col: 2}
+	for i := 0; i < 20; i++ {
+		mv, err := NewSkillPlayer(1, 0, nil).Move(b)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if *mv == win {
+			t.Errorf("skill 0 played the winning move")
+		}
+		mv, err = NewSkillPlayer(1, 1, nil).Move(b)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if *mv != win {
+			t.Errorf("skill 1 missed the winning move, played %v", *mv)
+		}
+	}
+}
+
+func TestSkillLevels(t *testing.T) {
+	losses := func(skill float64) int {
+		n := 0
+		for i := 0; i < 100; i++ {
+			if playGame(t, NewSkillPlayer(1, skill, nil), NewMinimaxPlayer(2, TieRandom)) == 2 {
+				n++
+			}
+		}
+		return n
+	}
+	easy, perfect := losses(SkillEasy), losses(SkillPerfect)
+	if perfect != 0 {
+		t.Errorf("perfect skill lost %d games against minimax", perfect)
+	}
+	if easy < 50 {
+		t.Errorf("easy skill lost only %d of 100 games against minimax", easy)
+	}
+}
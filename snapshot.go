@@ -0,0 +1,130 @@
+package tictactoe
+
+import (
+	"bytes"
+	"math"
+
+	"bigfunbrewing.com/tensor"
+)
+
+// Snapshotter is implemented by players that can copy themselves, so games
+// can be played on other goroutines while the original keeps training.
+type Snapshotter interface {
+	// Snapshot returns a player that moves the way this one does now and
+	// can move concurrently with it and with other snapshots. Training a
+	// snapshot does not change the original.
+	Snapshot() Player
+}
+
+// Snapshot returns a snapshot of p, or false if p cannot be copied.
+func Snapshot(p Player) (Player, bool) {
+	s, ok := p.(Snapshotter)
+	if !ok {
+		return nil, false
+	}
+	return s.Snapshot(), true
+}
+
+// copyNetwork overwrites the weights of dst, which must have the same shape,
+// with those of src.
+func copyNetwork(dst, src *tensor.Network[float64]) {
+	var buf bytes.Buffer
+	src.Write(&buf)
+	dst.Read(&buf)
+}
+
+// copyExplorer returns an explorer that explores like e without sharing its
+// rate. UCB explorers are shared, they lock their counts and every copy
+// should see the moves the others tried.
+func copyExplorer(e Explorer) Explorer {
+	switch e := e.(type) {
+	case *EpsilonGreedy:
+		c := *e
+		return &c
+	case *Boltzmann:
+		c := *e
+		return &c
+	}
+	return e
+}
+
+// Random, minimax and heuristic players keep nothing between moves
+// that a move changes, so they are their own snapshot.
+
+func (rp *RandomPlayer) Snapshot() Player {
+	return rp
+}
+
+func (mp *MinimaxPlayer) Snapshot() Player {
+	return mp
+}
+
+func (hp *HeuristicPlayer) Snapshot() Player {
+	return hp
+}
+
+// Snapshot shares the search settings but not the rollout players, which
+// may be learning players that change as they move. A rollout player that
+// cannot be copied is replaced by a new one from the rollout policy.
+func (mp *MCTSPlayer) Snapshot() Player {
+	out := *mp
+	for i, p := range mp.rollout {
+		if s, ok := Snapshot(p); ok {
+			out.rollout[i] = s
+		} else {
+			out.rollout[i] = mp.policy(i + 1)
+		}
+	}
+	return &out
+}
+
+// Snapshot copies the network, the exploration and how the player looks
+// ahead. The copy has no replay buffer.
+func (mp *MlannPlayer) Snapshot() Player {
+	out := &MlannPlayer{
+		pid:       mp.pid,
+		epsilon:   mp.epsilon,
+		gamma:     mp.gamma,
+		explore:   copyExplorer(mp.explore),
+		cfg:       mp.cfg,
+		episodes:  mp.episodes,
+		target:    mp.target,
+		lambda:    mp.lambda,
+		lookahead: mp.lookahead,
+		net:       newMlannNetwork(mp.cfg),
+		loss:      math.NaN(),
+	}
+	copyNetwork(out.net, mp.net)
+	return out
+}
+
+func (gp *GruPlayer) Snapshot() Player {
+	out := NewGruPlayer(gp.pid, gp.epsilon)
+	var buf bytes.Buffer
+	gp.gru.Write(&buf)
+	out.gru.Read(&buf)
+	copyNetwork(out.output, gp.output)
+	out.explore = copyExplorer(gp.explore)
+	out.lookahead = gp.lookahead
+	out.rollouts = gp.rollouts
+	out.episodes = gp.episodes
+	return out
+}
+
+// Snapshot only copies the actor, the critic is not needed to move.
+func (pp *PolicyPlayer) Snapshot() Player {
+	out := NewPolicyPlayer(pp.pid, pp.gamma, false)
+	copyNetwork(out.actor, pp.actor)
+	out.step = pp.step
+	out.episodes = pp.episodes
+	return out
+}
+
+func (qp *QTablePlayer) Snapshot() Player {
+	out := NewQTablePlayer(qp.pid, qp.epsilon, qp.alpha, qp.gamma)
+	for k, q := range qp.table {
+		c := *q
+		out.table[k] = &c
+	}
+	return out
+}
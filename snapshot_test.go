@@ -0,0 +1,91 @@
+package tictactoe
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSnapshotIndependent(t *testing.T) {
+	qp := NewQTablePlayer(1, 0.2, 0.5, 0.9)
+	qp.Train([]*GamePlayed{recordGame(t, NewMinimaxPlayer(1, TieFirst), NewRandomPlayer(2))})
+	p, ok := Snapshot(qp)
+	if !ok {
+		t.Fatal("expected a q-table player to snapshot")
+	}
+	snap := p.(*QTablePlayer)
+	if len(snap.table) != len(qp.table) {
+		t.Errorf("expected %d states in the snapshot, got %d", len(qp.table), len(snap.table))
+	}
+	for k, q := range qp.table {
+		if *snap.table[k] != *q {
+			t.Errorf("state %s: expected %v, got %v", k, *q, *snap.table[k])
+		}
+		q[0] += 1
+		if *snap.table[k] == *q {
+			t.Errorf("state %s: changing the player changed the snapshot", k)
+		}
+	}
+
+	mp := NewMlannPlayer(1, 0.1, 0.9)
+	snap2 := mp.Snapshot().(*MlannPlayer)
+	mp.SetEpsilon(0.5)
+	if snap2.explore.Rate() != 0.1 {
+		t.Errorf("expected the snapshot to keep exploring at 0.1, got %f", snap2.explore.Rate())
+	}
+	if snap2.net == mp.net {
+		t.Errorf("expected the snapshot to have its own network")
+	}
+
+	rollout := func(pid int) Player { return NewMlannPlayer(pid, 0.1, 0.9) }
+	mcts := NewMCTSPlayer(1, 10, 0, 1.4, rollout)
+	snap3 := mcts.Snapshot().(*MCTSPlayer)
+	for i := range mcts.rollout {
+		if snap3.rollout[i] == mcts.rollout[i] {
+			t.Errorf("expected the snapshot to have its own rollout player %d", i+1)
+		}
+	}
+
+	if _, ok := Snapshot(NewAlphaZeroPlayer(1, 10, 1.4, 1)); ok {
+		t.Errorf("an alphazero player records its searches and cannot be copied")
+	}
+}
+
+func TestSnapshotConcurrent(t *testing.T) {
+	// snapshots play on their own goroutines while the original trains,
+	// run with -race to check they share nothing
+	qp := NewQTablePlayer(1, 0.2, 0.5, 0.9)
+	ucb := NewUCBExplorer(0.5)
+	mp := NewMlannPlayer(2, 0.1, 0.9)
+	mp.SetExplorer(ucb)
+	games := make(chan *GamePlayed)
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		p1, _ := Snapshot(qp)
+		p2, _ := Snapshot(mp)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				g, _, err := PlayGame(p1, p2)
+				if err != nil {
+					t.Error(err.Error())
+					return
+				}
+				games <- g
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(games)
+	}()
+	n := 0
+	for g := range games {
+		qp.Train([]*GamePlayed{g})
+		ucb.SetRate(0.4)
+		n++
+	}
+	if n != 200 {
+		t.Errorf("expected 200 games, got %d", n)
+	}
+}
@@ -0,0 +1,85 @@
+package tictactoe
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NewPlayerFromSpec builds the player described by spec to play as pid. A
+// spec is the player type optionally followed by a colon and an argument,
+//
+//	randoplayer, heuristicplayer
+//	minimaxplayer[:tiebreak], random by default
+//	mctsplayer[:playouts], 1000 by default
+//	skillplayer[:skill], perfect by default
+//	mlannplayer:path, gruplayer:path, alphazeroplayer:path, qtableplayer:path,
+//	policyplayer:path
+//
+// Players with a saved model load it from path and play without exploring. A
+// model trained for the other side fails with a WrongSide error.
+// {pid} in the path is replaced by pid, so mlannplayer:player{pid}.net plays
+// each side with the network trained for it.
+func NewPlayerFromSpec(spec string, pid int) (Player, error) {
+	kind, arg, _ := strings.Cut(spec, ":")
+	arg = strings.ReplaceAll(arg, "{pid}", strconv.Itoa(pid))
+	var p Player
+	switch kind {
+	case "randoplayer":
+		return NewRandomPlayer(pid), nil
+	case "heuristicplayer":
+		return NewHeuristicPlayer(pid), nil
+	case "minimaxplayer":
+		if arg == "" {
+			arg = "random"
+		}
+		tie, err := ParseTieBreak(arg)
+		if err != nil {
+			return nil, err
+		}
+		return NewMinimaxPlayer(pid, tie), nil
+	case "mctsplayer":
+		playouts := 1000
+		if arg != "" {
+			n, err := strconv.Atoi(arg)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("bad number of playouts %q in %q", arg, spec)
+			}
+			playouts = n
+		}
+		return NewMCTSPlayer(pid, playouts, 0, 1.4, RandomRollout), nil
+	case "skillplayer":
+		if arg == "" {
+			arg = "perfect"
+		}
+		skill, err := ParseSkill(arg)
+		if err != nil {
+			return nil, err
+		}
+		return NewSkillPlayer(pid, skill, nil), nil
+	case "mlannplayer":
+		p = NewMlannPlayer(pid, 0, 0.9)
+	case "gruplayer":
+		p = NewGruPlayer(pid, 0)
+	case "alphazeroplayer":
+		p = NewAlphaZeroPlayer(pid, 200, 1.4, 0)
+	case "qtableplayer":
+		p = NewQTablePlayer(pid, 0, 0.5, 0.9)
+	case "policyplayer":
+		// the critic is only used in training, match whatever the file has
+		baseline := true
+		if h, err := ReadModelHeader(arg); err == nil {
+			baseline = h.Hyper["baseline"] == 1
+		}
+		p = NewPolicyPlayer(pid, 0.9, baseline)
+	default:
+		return nil, fmt.Errorf("unknown player type %q", kind)
+	}
+	if arg == "" {
+		return nil, fmt.Errorf("%s needs the path of a saved model, e.g. %s:player%d.net", kind, kind, pid)
+	}
+	if err := p.Load(arg); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
@@ -0,0 +1,55 @@
+package tictactoe
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewPlayerFromSpec(t *testing.T) {
+	dir := t.TempDir()
+	qtable := filepath.Join(dir, "q.json")
+	if err := NewQTablePlayer(1, 0.1, 0.5, 0.9).Persist(qtable); err != nil {
+		t.Fatal(err.Error())
+	}
+	for pid := 1; pid <= 2; pid++ {
+		if err := NewMlannPlayer(pid, 0, 0.9).Persist(filepath.Join(dir, fmt.Sprintf("player%d.net", pid))); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	policy := filepath.Join(dir, "policy.net")
+	if err := NewPolicyPlayer(1, 0.9, false).Persist(policy); err != nil {
+		t.Fatal(err.Error())
+	}
+	type test struct {
+		in  string
+		out Player
+		err bool
+	}
+	tests := []test{
+		{in: "randoplayer", out: &RandomPlayer{}},
+		{in: "minimaxplayer:fastest", out: &MinimaxPlayer{}},
+		{in: "mctsplayer:50", out: &MCTSPlayer{}},
+		{in: "skillplayer:easy", out: &SkillPlayer{}},
+		{in: "qtableplayer:" + qtable, out: &QTablePlayer{}},
+		{in: "policyplayer:" + policy, out: &PolicyPlayer{}},
+		{in: "mlannplayer:" + filepath.Join(dir, "player{pid}.net"), out: &MlannPlayer{}},
+		{in: "mlannplayer:" + filepath.Join(dir, "player1.net"), err: true},
+		{in: "mlannplayer", err: true},
+		{in: "mlannplayer:" + filepath.Join(dir, "missing.net"), err: true},
+		{in: "mctsplayer:many", err: true},
+		{in: "chessplayer", err: true},
+	}
+	for i := range tests {
+		p, err := NewPlayerFromSpec(tests[i].in, 2)
+		if (err != nil) != tests[i].err {
+			t.Errorf("test %d: unexpected error %v", i, err)
+			continue
+		}
+		if err == nil {
+			if got, want := fmt.Sprintf("%T", p), fmt.Sprintf("%T", tests[i].out); got != want {
+				t.Errorf("test %d: expected a %s, got a %s", i, want, got)
+			}
+		}
+	}
+}
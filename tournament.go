@@ -0,0 +1,172 @@
+package tictactoe
+
+import (
+	"fmt"
+)
+
+// PlayGame plays a game between player1 and player2 and returns the game and
+// its outcome, 1 or 2 for the winner and -1 for a tie as from Board.GameOver.
+// If a player fails to move the game so far is returned with the error and
+// outcome 0.
+func PlayGame(player1, player2 Player) (*GamePlayed, int, error) {
+	b := NewBoard()
+	b.Reset()
+	players := []Player{player1, player2}
+	for turn := 0; ; turn++ {
+		mv, err := players[turn%2].Move(b)
+		if err != nil {
+			return b.GamePlayed(), 0, fmt.Errorf("player %d could not move: %w", turn%2+1, err)
+		}
+		if err := b.Move(mv); err != nil {
+			return b.GamePlayed(), 0, fmt.Errorf("player %d made an invalid move: %w", turn%2+1, err)
+		}
+		if w := b.GameOver(); w != 0 {
+			return b.GamePlayed(), w, nil
+		}
+	}
+}
+
+// Record counts the games an entrant won, lost and tied.
+type Record struct {
+	Wins   int
+	Losses int
+	Draws  int
+}
+
+func (r Record) Games() int {
+	return r.Wins + r.Losses + r.Draws
+}
+
+// Score is the points scored, 1 for a win and 1/2 for a tie, over the games
+// played.
+func (r Record) Score() float64 {
+	if r.Games() == 0 {
+		return 0
+	}
+	return (float64(r.Wins) + float64(r.Draws)/2) / float64(r.Games())
+}
+
+func (r *Record) add(o Record) {
+	r.Wins += o.Wins
+	r.Losses += o.Losses
+	r.Draws += o.Draws
+}
+
+// Result is a tournament game between entrants X, who moved first, and O.
+// Winner is X or O, or -1 for a tie.
+type Result struct {
+	X      int
+	O      int
+	Winner int
+}
+
+// Score is the points entrant i scored in the game.
+func (r Result) Score(i int) float64 {
+	switch r.Winner {
+	case -1:
+		return 0.5
+	case i:
+		return 1
+	}
+	return 0
+}
+
+// Tournament is the outcome of a round robin.
+type Tournament struct {
+	Names []string
+	// Side is the only side an entrant plays, 1 or 2, or 0 if it plays both.
+	Side []int
+	// Rounds holds the results round by round, each round is one game of
+	// every pairing.
+	Rounds [][]Result
+}
+
+// Entrant is a tournament player, New builds it to play as pid.
+type Entrant struct {
+	Name string
+	New  func(pid int) (Player, error)
+}
+
+// RoundRobin plays every entrant against every other, games times as X and
+// games times as O. Each entrant is built once as player 1 and once as
+// player 2 and keeps playing from the same instance. An entrant whose model
+// was trained for one side, so New fails with a WrongSide error for the
+// other, only plays the games where it has that side.
+func RoundRobin(entrants []Entrant, games int) (*Tournament, error) {
+	players := make([][2]Player, len(entrants))
+	t := &Tournament{Names: make([]string, len(entrants)), Side: make([]int, len(entrants))}
+	for i, e := range entrants {
+		t.Names[i] = e.Name
+		for pid := 1; pid <= 2; pid++ {
+			p, err := e.New(pid)
+			if WrongSide(err) && t.Side[i] == 0 {
+				t.Side[i] = other(pid)
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", e.Name, err)
+			}
+			players[i][pid-1] = p
+		}
+	}
+	for g := 0; g < games; g++ {
+		round := make([]Result, 0)
+		for x := range players {
+			for o := range players {
+				if x == o || players[x][0] == nil || players[o][1] == nil {
+					continue
+				}
+				_, w, err := PlayGame(players[x][0], players[o][1])
+				if err != nil {
+					return nil, fmt.Errorf("%s vs %s: %w", t.Names[x], t.Names[o], err)
+				}
+				r := Result{X: x, O: o, Winner: -1}
+				switch w {
+				case 1:
+					r.Winner = x
+				case 2:
+					r.Winner = o
+				}
+				round = append(round, r)
+			}
+		}
+		t.Rounds = append(t.Rounds, round)
+	}
+	return t, nil
+}
+
+// CrossTable returns table[i][j], the record of entrant i against entrant j
+// with either to move first.
+func (t *Tournament) CrossTable() [][]Record {
+	table := make([][]Record, len(t.Names))
+	for i := range table {
+		table[i] = make([]Record, len(t.Names))
+	}
+	for _, round := range t.Rounds {
+		for _, r := range round {
+			switch r.Winner {
+			case -1:
+				table[r.X][r.O].Draws++
+				table[r.O][r.X].Draws++
+			case r.X:
+				table[r.X][r.O].Wins++
+				table[r.O][r.X].Losses++
+			default:
+				table[r.O][r.X].Wins++
+				table[r.X][r.O].Losses++
+			}
+		}
+	}
+	return table
+}
+
+// Totals returns the record of every entrant over the whole tournament.
+func (t *Tournament) Totals() []Record {
+	out := make([]Record, len(t.Names))
+	for i, row := range t.CrossTable() {
+		for j := range row {
+			out[i].add(row[j])
+		}
+	}
+	return out
+}
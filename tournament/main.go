@@ -0,0 +1,101 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"bigfunbrewing.com/tictactoe"
+)
+
+func main() {
+	games := flag.Int("games", 10, "number of games every pair of players plays with each of them moving first")
+	k := flag.Float64("k", 16, "elo K factor, how far a rating moves after every game")
+	c := flag.Float64("c", 30, "glicko deviation added before every round")
+	flag.Usage = func() {
+		fmt.Println("usage: tournament [flags] spec spec ...")
+		fmt.Println()
+		fmt.Println("each spec is a player type optionally followed by a colon and a model path or argument, e.g.")
+		fmt.Println("mlannplayer:player1.net mlannplayer:player{pid}.net gruplayer:gplayer2.net randoplayer minimaxplayer mctsplayer:200 skillplayer:medium")
+		fmt.Println()
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	specs := flag.Args()
+	if len(specs) < 2 {
+		flag.Usage()
+		return
+	}
+
+	entrants := make([]tictactoe.Entrant, len(specs))
+	for i := range specs {
+		spec := specs[i]
+		entrants[i] = tictactoe.Entrant{
+			Name: spec,
+			New:  func(pid int) (tictactoe.Player, error) { return tictactoe.NewPlayerFromSpec(spec, pid) },
+		}
+	}
+	t, err := tictactoe.RoundRobin(entrants, *games)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	elo := tictactoe.Elo(t, *k)
+	glicko := tictactoe.Glicko(t, *c)
+	totals := t.Totals()
+	table := t.CrossTable()
+
+	// best first
+	order := make([]int, len(specs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return elo[order[a]].Value > elo[order[b]].Value })
+
+	// names are padded to the same width so they line up on the left
+	width := 0
+	for i := range t.Names {
+		if len(t.Names[i]) > width {
+			width = len(t.Names[i])
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(w, "\t")
+	for j := range order {
+		fmt.Fprintf(w, "%d\t", j+1)
+	}
+	fmt.Fprintln(w, "total\tscore\t")
+	for n, i := range order {
+		fmt.Fprintf(w, "%d %-*s\t", n+1, width, t.Names[i])
+		for _, j := range order {
+			if i == j {
+				fmt.Fprint(w, "-\t")
+				continue
+			}
+			r := table[i][j]
+			fmt.Fprintf(w, "%d-%d-%d\t", r.Wins, r.Losses, r.Draws)
+		}
+		r := totals[i]
+		fmt.Fprintf(w, "%d-%d-%d\t%.3f\t\n", r.Wins, r.Losses, r.Draws, r.Score())
+	}
+	w.Flush()
+	fmt.Println("records are wins-losses-draws of the row player against the column player")
+	for _, i := range order {
+		if t.Side[i] != 0 {
+			fmt.Printf("%s only plays as player %d\n", t.Names[i], t.Side[i])
+		}
+	}
+	fmt.Println()
+
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "%-*s\telo\tglicko\tdeviation\t\n", width, "player")
+	for _, i := range order {
+		fmt.Fprintf(w, "%-*s\t%.0f\t%.0f\t%.0f\t\n", width, t.Names[i], elo[i].Value, glicko[i].Value, glicko[i].Dev)
+	}
+	w.Flush()
+}
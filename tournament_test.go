@@ -0,0 +1,82 @@
+package tictactoe
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRoundRobin(t *testing.T) {
+	entrants := []Entrant{
+		{Name: "minimax", New: func(pid int) (Player, error) { return NewMinimaxPlayer(pid, TieRandom), nil }},
+		{Name: "heuristic", New: func(pid int) (Player, error) { return NewHeuristicPlayer(pid), nil }},
+		{Name: "random", New: func(pid int) (Player, error) { return NewRandomPlayer(pid), nil }},
+	}
+	tour, err := RoundRobin(entrants, 10)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(tour.Rounds) != 10 || len(tour.Rounds[0]) != 6 {
+		t.Fatalf("expected 10 rounds of 6 games, got %d rounds", len(tour.Rounds))
+	}
+	table := tour.CrossTable()
+	for i := range table {
+		if table[i][i].Games() != 0 {
+			t.Errorf("%s played itself", tour.Names[i])
+		}
+		for j := range table {
+			if i != j && table[i][j].Games() != 20 {
+				t.Errorf("expected 20 games of %s against %s, got %d", tour.Names[i], tour.Names[j], table[i][j].Games())
+			}
+			if table[i][j].Wins != table[j][i].Losses || table[i][j].Draws != table[j][i].Draws {
+				t.Errorf("%s against %s does not mirror %v %v", tour.Names[i], tour.Names[j], table[i][j], table[j][i])
+			}
+		}
+	}
+	if table[0][1].Wins != 0 || table[0][1].Losses != 0 {
+		t.Errorf("expected minimax and heuristic to always tie, got %v", table[0][1])
+	}
+	totals := tour.Totals()
+	if totals[0].Losses != 0 || totals[2].Score() > 0.5 {
+		t.Errorf("unexpected totals %v", totals)
+	}
+}
+
+func TestRoundRobinOneSide(t *testing.T) {
+	// a network trained as player 1 sits out the games as O
+	path := filepath.Join(t.TempDir(), "player1.net")
+	if err := NewMlannPlayer(1, 0, 0.9).Persist(path); err != nil {
+		t.Fatal(err.Error())
+	}
+	entrants := []Entrant{
+		{Name: "mlann", New: func(pid int) (Player, error) { return NewPlayerFromSpec("mlannplayer:"+path, pid) }},
+		{Name: "minimax", New: func(pid int) (Player, error) { return NewMinimaxPlayer(pid, TieRandom), nil }},
+		{Name: "random", New: func(pid int) (Player, error) { return NewRandomPlayer(pid), nil }},
+	}
+	tour, err := RoundRobin(entrants, 5)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if tour.Side[0] != 1 || tour.Side[1] != 0 {
+		t.Errorf("expected only the mlann player to play one side, got %v", tour.Side)
+	}
+	for _, round := range tour.Rounds {
+		if len(round) != 4 {
+			t.Fatalf("expected 4 games a round, got %d", len(round))
+		}
+		for _, r := range round {
+			if r.O == 0 {
+				t.Errorf("the mlann player played as O against %s", tour.Names[r.X])
+			}
+		}
+	}
+	if n := tour.CrossTable()[0][1].Games(); n != 5 {
+		t.Errorf("expected 5 games of mlann against minimax, got %d", n)
+	}
+}
+
+func TestPlayGame(t *testing.T) {
+	_, w, err := PlayGame(NewMinimaxPlayer(1, TieFirst), NewMinimaxPlayer(2, TieFirst))
+	if err != nil || w != -1 {
+		t.Errorf("expected perfect play to tie, got %d %v", w, err)
+	}
+}